@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// passes averaged before accumulation stops, past this the image hardly changes
+const accumPasses = 64
+
+// jittered samples are taken on a grid of accumGrid*accumGrid positions inside each pixel
+const accumGrid = 16
+
+// accumulator averages renders of a still view, each sampled at a random
+// offset inside the pixels, into an anti-aliased image
+type accumulator struct {
+	sums   []uint32 // per channel sums of every pass
+	passes int
+	values []float64 // the latest pass
+	pixels []byte
+}
+
+// accumulateFrame adds another jittered pass of the w*h view to the frame.
+// reset starts over from the frame just rendered, after the view or its
+// colours changed.
+func (g *Game) accumulateFrame(w, h int, reset bool) {
+	a := &g.accum
+	if len(a.sums) != w*h*4 {
+		a.sums = make([]uint32, w*h*4)
+		a.values = make([]float64, w*h)
+		a.pixels = make([]byte, w*h*4)
+		reset = true
+	}
+	if reset {
+		for i, p := range g.pixels {
+			a.sums[i] = uint32(p)
+		}
+		a.passes = 1
+		return
+	}
+	if a.passes >= accumPasses {
+		return
+	}
+
+	maxIter := g.frameKey.view.maxIter
+	sample := g.pixelSampler(w*accumGrid, h*accumGrid, maxIter)
+	jx, jy := rand.IntN(accumGrid), rand.IntN(accumGrid)
+	g.parallelRows(h, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < w; x++ {
+				a.values[y*w+x] = sample(x*accumGrid+jx, y*accumGrid+jy)
+			}
+		}
+	})
+	g.colorFrame(a.pixels, a.values, w, h, 1, maxIter)
+
+	a.passes++
+	for i, p := range a.pixels {
+		a.sums[i] += uint32(p)
+		a.pixels[i] = uint8(a.sums[i] / uint32(a.passes))
+	}
+	g.frame.WritePixels(a.pixels)
+}
+
+func (g *Game) accumInfo() string {
+	if !g.accumulate {
+		return "Accumulate: Off"
+	}
+	return fmt.Sprintf("Accumulate: %d/%d", g.accum.passes, accumPasses)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// runBench renders the starting view n times at window size into an
+// offscreen buffer and prints how long it took
+func runBench(g *Game, n int) {
+	w, h := g.viewSize()
+	pixels := make([]byte, w*h*4)
+
+	var total time.Duration
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		values := computeFrame(g, w, h)
+		g.colorFrame(pixels, values, w, h, 1, g.currentMaxIter())
+		total += time.Since(start)
+	}
+	fmt.Printf("rendered %s %dx%d at zoom %g, %d iterations\n", fractalName(g.fractalType), w, h, g.zoom, g.currentMaxIter())
+	fmt.Printf("%d frames in %v, %v per frame on %d workers\n", n, total, total/time.Duration(n), workerCount)
+}
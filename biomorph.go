@@ -0,0 +1,53 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// biomorph orbits escape past this radius, and either part of the escaped z
+// still under it marks a biomorph
+const biomorphRadius = 10.0
+
+// biomorph iterates z -> z^2 + c from z like julia, reporting alongside the
+// smooth iteration count Pickover's test on the escaped z: whether its real or
+// imaginary part is still smaller than biomorphRadius. Those points grow into
+// the cell-like shapes around the set.
+func biomorph(x, y, cx, cy float64, maxIter int) (float64, bool) {
+	bailout := biomorphRadius * biomorphRadius
+	iteration := 0
+	for x*x+y*y <= bailout && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = 2*x*y + cy
+		x = xTemp
+		iteration++
+	}
+
+	if iteration < maxIter {
+		v := smoothIter(iteration, x*x+y*y, biomorphRadius, 2)
+		return v, math.Abs(x) < biomorphRadius || math.Abs(y) < biomorphRadius
+	}
+	return interiorValue(x, y, maxIter), false
+}
+
+// biomorphValue packs the result of biomorph into one frame value, biomorph
+// points as the negated iteration count
+func biomorphValue(v float64, isBiomorph bool) float64 {
+	if isBiomorph {
+		return -v
+	}
+	return v
+}
+
+// biomorphColor colours biomorph points from the palette like escaped points
+// usually are, leaving the rest of the outside a dim shadow of it
+func (g *Game) biomorphColor(v float64, maxIter int, palette []color.RGBA, style blendStyle) color.RGBA {
+	if v >= float64(maxIter) {
+		return g.valueColor(v, maxIter, palette, nil, iterBand{}, style)
+	}
+	if v < 0 {
+		return getColor(-v, maxIter, palette, g.colorDensity, g.paletteOffset, style)
+	}
+	clr := getColor(v, maxIter, palette, g.colorDensity, g.paletteOffset, style)
+	return color.RGBA{clr.R / 4, clr.G / 4, clr.B / 4, 255}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+)
+
+const bookmarksPath = "bookmarks.json"
+
+// Bookmark is a saved view that can be restored later
+type Bookmark struct {
+	Name        string  `json:"name"`
+	FractalType int     `json:"fractalType"`
+	CenterX     float64 `json:"centerX"`
+	CenterY     float64 `json:"centerY"`
+	CenterLoX   float64 `json:"centerLoX,omitempty"` // lo parts of the double-double center
+	CenterLoY   float64 `json:"centerLoY,omitempty"`
+	JuliaX      float64 `json:"juliaX"`
+	JuliaY      float64 `json:"juliaY"`
+	Zoom        float64 `json:"zoom"`
+	MaxIter     int     `json:"maxIter"`
+	Power       int     `json:"power"`
+	TransFunc   int     `json:"transFunc"`
+}
+
+// LoadBookmarks reads the bookmarks saved at path. A missing file is not an error.
+func LoadBookmarks(path string) ([]Bookmark, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []Bookmark
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// a bad entry, edited by hand or from an older version, is left out
+	// rather than losing the rest
+	valid := bookmarks[:0]
+	for _, b := range bookmarks {
+		// bookmarks saved before the power was kept are of the standard set
+		if b.Power == 0 {
+			b.Power = 2
+		}
+		if err := b.validate(); err != nil {
+			log.Printf("%s: skipping bookmark %q: %v", path, b.Name, err)
+			continue
+		}
+		valid = append(valid, b)
+	}
+	return valid, nil
+}
+
+// validate checks b against the ranges the controls keep the game in, as State.validate does
+func (b Bookmark) validate() error {
+	switch {
+	case b.FractalType < 0 || b.FractalType >= fractalCount:
+		return fmt.Errorf("unknown fractal type %d", b.FractalType)
+	case b.TransFunc < 0 || b.TransFunc >= transFuncCount:
+		return fmt.Errorf("unknown transcendental function %d", b.TransFunc)
+	case !finite(b.CenterX) || !finite(b.CenterY) || !finite(b.CenterLoX) || !finite(b.CenterLoY) || !finite(b.JuliaX) || !finite(b.JuliaY):
+		return fmt.Errorf("coordinates must be finite")
+	case !finite(b.Zoom) || b.Zoom < 1 || b.Zoom > maxZoom:
+		return fmt.Errorf("zoom %v out of range", b.Zoom)
+	case b.MaxIter < 1:
+		return fmt.Errorf("max iter %d out of range", b.MaxIter)
+	case b.Power < 2 || b.Power > 8:
+		return fmt.Errorf("power %d out of range", b.Power)
+	}
+	return nil
+}
+
+// SaveBookmarks writes bookmarks to path, replacing its contents
+func SaveBookmarks(path string, bookmarks []Bookmark) error {
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// addBookmark saves the current view to the bookmarks file
+func (g *Game) addBookmark() {
+	b := Bookmark{
+		Name:        fmt.Sprintf("%s %d", fractalName(g.fractalType), len(g.bookmarks)+1),
+		FractalType: g.fractalType,
+		CenterX:     g.centerX,
+		CenterY:     g.centerY,
+		CenterLoX:   g.centerLoX,
+		CenterLoY:   g.centerLoY,
+		JuliaX:      g.juliaX,
+		JuliaY:      g.juliaY,
+		Zoom:        g.zoom,
+		MaxIter:     g.currentMaxIter(),
+		Power:       g.power,
+		TransFunc:   g.transFunc,
+	}
+	g.bookmarks = append(g.bookmarks, b)
+	g.bookmarkIndex = len(g.bookmarks) - 1
+
+	if err := SaveBookmarks(bookmarksPath, g.bookmarks); err != nil {
+		log.Printf("saving bookmarks: %v", err)
+		return
+	}
+	log.Printf("bookmarked %q", b.Name)
+}
+
+// nextBookmark restores the bookmark after the last one visited
+func (g *Game) nextBookmark() {
+	if len(g.bookmarks) == 0 {
+		return
+	}
+	g.bookmarkIndex = (g.bookmarkIndex + 1) % len(g.bookmarks)
+	g.applyBookmark(g.bookmarks[g.bookmarkIndex])
+}
+
+func (g *Game) applyBookmark(b Bookmark) {
+	g.switchFractal(b.FractalType)
+	g.power, g.transFunc = b.Power, b.TransFunc
+	g.setPreciseCenter(twoSum(b.CenterX, b.CenterLoX), twoSum(b.CenterY, b.CenterLoY))
+	g.juliaX, g.juliaY = b.JuliaX, b.JuliaY
+	g.zoom = b.Zoom
+	g.maxIter = b.MaxIter
+	g.autoIter = false
+}
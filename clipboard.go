@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.design/x/clipboard"
+)
+
+// how long "Copied!" stays up after copying the view
+const copiedMessageTime = 2 * time.Second
+
+// the clipboard is set up on first use, so a machine without one only fails when copying
+var clipboardInit = sync.OnceValue(clipboard.Init)
+
+// viewText describes the current view, its center in the form -center takes
+func (g *Game) viewText() string {
+	x, y := g.preciseCenter()
+	return fmt.Sprintf("center=(%v,%v) zoom=%.17g type=%s", x, y, g.zoom, fractalName(g.fractalType))
+}
+
+// copyView puts the current view on the system clipboard
+func (g *Game) copyView() {
+	if err := clipboardInit(); err != nil {
+		log.Printf("copying view: %v", err)
+		return
+	}
+	clipboard.Write(clipboard.FmtText, []byte(g.viewText()))
+	g.copiedAt = time.Now()
+}
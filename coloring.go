@@ -0,0 +1,437 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// how frame values are mapped onto the palette
+const (
+	ColorDirect    = iota // palette position follows the smooth iteration count
+	ColorHistogram        // palette position follows the iteration count's rank in the frame
+	ColorDistance         // brightness follows the estimated distance to the set's boundary
+	ColorOrbitTrap        // palette position follows how close the orbit came to a trap shape
+	ColorLog              // palette position follows the log of the iteration count across the frame's range
+	ColorPotential        // palette position follows the continuous potential, log|z_n| / 2^n
+
+	colorModeCount // number of colouring modes, keep last
+)
+
+func colorModeName(mode int) string {
+	switch mode {
+	case ColorDirect:
+		return "Direct"
+	case ColorHistogram:
+		return "Histogram"
+	case ColorDistance:
+		return "Distance"
+	case ColorOrbitTrap:
+		return "Orbit Trap"
+	case ColorLog:
+		return "Log Scale"
+	case ColorPotential:
+		return "Potential"
+	default:
+		return "Unknown"
+	}
+}
+
+// what the values of a frame hold
+const (
+	valuesIterations = iota // smooth iteration counts (or packed roots)
+	valuesDistance          // estimated distances to the boundary
+	valuesTrap              // closest approach of the orbit to the trap shape
+	valuesBiomorph          // smooth iteration counts, negated for biomorph points
+)
+
+// valueKind reports what the values of the current frame hold. Distance
+// estimation and orbit traps are only implemented for the float64 Mandelbrot,
+// biomorphs for it and the Julia.
+func (g *Game) valueKind() int {
+	if g.biomorph && (g.fractalType == FractalJulia || g.fractalType == FractalMandelbrot && g.power == 2) && !g.useBigFloat() && !g.useDD() {
+		return valuesBiomorph
+	}
+	if g.fractalType != FractalMandelbrot || g.power != 2 || g.useBigFloat() || g.useDD() {
+		return valuesIterations
+	}
+	switch g.colorMode {
+	case ColorDistance:
+		return valuesDistance
+	case ColorOrbitTrap:
+		return valuesTrap
+	}
+	return valuesIterations
+}
+
+func (g *Game) coloringInfo() string {
+	if g.heatmap {
+		return "Coloring: Iteration Heatmap"
+	}
+	info := fmt.Sprintf("Coloring: %s", colorModeName(g.colorMode))
+	if g.colorMode == ColorOrbitTrap {
+		info += fmt.Sprintf(" (%s)", g.trapShape)
+	}
+	if g.showEdges() {
+		info += ", Edges"
+	}
+	if g.valueKind() == valuesBiomorph {
+		info += ", Biomorphs"
+	}
+	return info
+}
+
+func (g *Game) colorCycleInfo() string {
+	if !g.colorCycle {
+		return "Color Cycle: Off"
+	}
+	return fmt.Sprintf("Color Cycle: %.0f/s", g.colorCycleSpeed)
+}
+
+// showSettle reports whether interior points are shaded by how quickly they
+// settle, which only the julia kernel tracks
+func (g *Game) showSettle() bool {
+	return g.interiorSettle && g.fractalType == FractalJulia
+}
+
+func (g *Game) interiorInfo() string {
+	if g.showSettle() {
+		return "Interior: Settling Speed"
+	}
+	if g.interiorColoring {
+		return "Interior: |z|"
+	}
+	return "Interior: Black"
+}
+
+// colorFrame maps the values of a frame to w*h RGBA pixels. values holds
+// samples*samples values per pixel, (w*samples)*(h*samples) in all, whose
+// colours are averaged.
+func (g *Game) colorFrame(pixels []byte, values []float64, w, h, samples, maxIter int) {
+	g.colorRows(pixels, values, w, samples, maxIter, g.frameStats(values, maxIter), 0, 0, h)
+}
+
+// frameStats is what colouring needs to know about the whole frame: the
+// histogram's cdf in histogram mode, and the band of escaped counts in log
+// scale mode
+type frameStats struct {
+	cdf  []float64
+	band iterBand
+}
+
+func (g *Game) frameStats(values []float64, maxIter int) frameStats {
+	var stats frameStats
+	if g.colorMode == ColorHistogram {
+		stats.cdf = histogramCDF(values, maxIter)
+	}
+	if g.colorMode == ColorLog {
+		stats.band = escapedBand(values, maxIter)
+	}
+	return stats
+}
+
+// colorRows colours the pixel rows startY to endY of a frame w pixels wide,
+// like colorFrame. values only holds value rows from top on, so a frame can
+// be coloured a strip at a time; edges need the value rows either side of
+// the strip too.
+func (g *Game) colorRows(pixels []byte, values []float64, w, samples, maxIter int, stats frameStats, top, startY, endY int) {
+	palette := g.palette()
+	cdf, band := stats.cdf, stats.band
+
+	valuesWidth := w * samples
+	valuesHeight := len(values) / valuesWidth
+	count := samples * samples
+	edges := g.showEdges()
+
+	kind := g.valueKind()
+	minX, maxX, _, _ := g.viewBounds()
+	pixelSize := (maxX - minX) / float64(valuesWidth)
+	curve := gammaCurve(g.gamma)
+
+	g.parallelRows(endY-startY, func(fromY, toY int) {
+		for y := startY + fromY; y < startY+toY; y++ {
+			for x := 0; x < w; x++ {
+				style := blendStyle{oklab: g.colorSpace == ColorSpaceOklab}
+				if g.dither {
+					style.dither = bayerThreshold(x, y)
+				}
+
+				var r, gr, b, a int
+				for sy := 0; sy < samples; sy++ {
+					row := (y*samples + sy - top) * valuesWidth
+					for sx := 0; sx < samples; sx++ {
+						v := values[row+x*samples+sx]
+						var clr color.RGBA
+						switch {
+						case g.heatmap && kind == valuesIterations:
+							clr = g.heatColor(v, maxIter)
+						case edges:
+							strength := edgeStrength(values, valuesWidth, valuesHeight, x*samples+sx, y*samples+sy-top, maxIter)
+							clr = edgeColor(strength, g.valueColor(v, maxIter, palette, cdf, band, style))
+						case kind == valuesDistance:
+							clr = distanceColor(v, pixelSize)
+						case kind == valuesTrap:
+							clr = trapColor(v, palette, g.paletteOffset, style)
+						case kind == valuesBiomorph:
+							clr = g.biomorphColor(v, maxIter, palette, style)
+						default:
+							clr = g.valueColor(v, maxIter, palette, cdf, band, style)
+						}
+						r += int(clr.R)
+						gr += int(clr.G)
+						b += int(clr.B)
+						a += int(clr.A)
+					}
+				}
+
+				i := (y*w + x) * 4
+				pixels[i] = curve[r/count]
+				pixels[i+1] = curve[gr/count]
+				pixels[i+2] = curve[b/count]
+				pixels[i+3] = uint8(a / count)
+			}
+		}
+	})
+}
+
+// gammaCurve maps every channel value v to 255*(v/255)^(1/gamma), a gamma
+// above 1 brightens the image
+func gammaCurve(gamma float64) [256]uint8 {
+	var curve [256]uint8
+	for v := range curve {
+		curve[v] = uint8(math.Round(255 * math.Pow(float64(v)/255, 1/gamma)))
+	}
+	return curve
+}
+
+// valueColor maps a single frame value to a colour. cdf is only set in
+// histogram mode and band in log scale mode.
+func (g *Game) valueColor(v float64, maxIter int, palette []color.RGBA, cdf []float64, band iterBand, style blendStyle) color.RGBA {
+	if findsRoots(g.fractalType) {
+		return getRootColor(unpackRoot(v))
+	}
+	if g.fractalType == FractalLyapunov {
+		return lyapunovColor(v)
+	}
+	if v >= float64(maxIter) && (g.interiorColoring || g.showSettle()) {
+		return interiorColor(v-float64(maxIter), palette, style)
+	}
+	if g.colorMode == ColorLog {
+		return logColor(v, maxIter, palette, band, g.paletteOffset, style)
+	}
+	if g.colorMode == ColorPotential {
+		return potentialColor(v, maxIter, palette, g.colorDensity, g.paletteOffset, style)
+	}
+	if cdf != nil {
+		return histogramColor(v, maxIter, palette, cdf, g.paletteOffset, style)
+	}
+	return getColor(v, maxIter, palette, g.colorDensity, g.paletteOffset, style)
+}
+
+// showEdges reports whether the frame is drawn as line art. Only escape
+// counts make sense to take the gradient of, not roots or exponents.
+func (g *Game) showEdges() bool {
+	return g.edges && g.valueKind() == valuesIterations && !findsRoots(g.fractalType) && g.fractalType != FractalLyapunov
+}
+
+// edgeStrength is the size of the Sobel gradient of the iteration counts
+// around sample (x, y) of values (w*h). Interior points count as maxIter, and
+// the frame's border repeats its outermost samples.
+func edgeStrength(values []float64, w, h, x, y, maxIter int) float64 {
+	at := func(dx, dy int) float64 {
+		sx := min(max(x+dx, 0), w-1)
+		sy := min(max(y+dy, 0), h-1)
+		return math.Min(values[sy*w+sx], float64(maxIter))
+	}
+	gx := at(1, -1) + 2*at(1, 0) + at(1, 1) - at(-1, -1) - 2*at(-1, 0) - at(-1, 1)
+	gy := at(-1, 1) + 2*at(0, 1) + at(1, 1) - at(-1, -1) - 2*at(0, -1) - at(1, -1)
+	return math.Hypot(gx, gy)
+}
+
+// a gradient of this many iterations lights an edge about two thirds of the way
+const edgeSoftness = 8
+
+// edgeColor darkens clr, the sample's usual colour, the weaker the edge through it
+func edgeColor(strength float64, clr color.RGBA) color.RGBA {
+	t := 1 - math.Exp(-strength/edgeSoftness)
+	return color.RGBA{uint8(float64(clr.R) * t), uint8(float64(clr.G) * t), uint8(float64(clr.B) * t), 255}
+}
+
+// 4x4 Bayer matrix, every threshold from 0 to 15 spread as evenly as possible
+var bayerMatrix = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// bayerThreshold is the ordered dithering offset of pixel (x, y), between 0 and 1
+func bayerThreshold(x, y int) float64 {
+	return (bayerMatrix[y%4][x%4] + 0.5) / 16
+}
+
+func (g *Game) ditherInfo() string {
+	if g.dither {
+		return "Dither: Bayer 4x4"
+	}
+	return "Dither: Off"
+}
+
+// heatColor is the debug heatmap, green for points that escape quickly
+// through to red for ones that take nearly maxIter. The interior is black.
+func (g *Game) heatColor(v float64, maxIter int) color.RGBA {
+	if findsRoots(g.fractalType) {
+		_, v = unpackRoot(v)
+	}
+	if v < 0 || v >= float64(maxIter) {
+		return color.RGBA{A: 255}
+	}
+	t := v / float64(maxIter)
+	return color.RGBA{uint8(255 * t), uint8(255 * (1 - t)), 0, 255}
+}
+
+// histogramCDF counts the escaped pixels at each whole iteration count and
+// returns the cumulative fraction of them at or below each count
+func histogramCDF(values []float64, maxIter int) []float64 {
+	cdf := make([]float64, maxIter)
+	total := 0.0
+	for _, v := range values {
+		if v > 0 && v < float64(maxIter) {
+			cdf[int(v)]++
+			total++
+		}
+	}
+	if total == 0 {
+		return cdf
+	}
+
+	sum := 0.0
+	for i, count := range cdf {
+		sum += count
+		cdf[i] = sum / total
+	}
+	return cdf
+}
+
+// histogramColor spreads the palette once over the escaped pixels by their rank in the frame
+func histogramColor(v float64, maxIter int, palette []color.RGBA, cdf []float64, offset float64, style blendStyle) color.RGBA {
+	if v <= 0 || v >= float64(maxIter) {
+		return color.RGBA{A: 255}
+	}
+
+	n := int(v)
+	lo := 0.0
+	if n > 0 {
+		lo = cdf[n-1]
+	}
+	t := lo + (cdf[n]-lo)*(v-float64(n))
+	return samplePalette(palette, t*float64(len(palette)-1)+offset, style)
+}
+
+// iterBand is the range of iteration counts the escaped pixels of a frame took
+type iterBand struct {
+	lo, hi float64
+}
+
+// escapedBand finds the lowest and highest iteration counts among the escaped pixels
+func escapedBand(values []float64, maxIter int) iterBand {
+	band := iterBand{lo: float64(maxIter), hi: 0}
+	for _, v := range values {
+		if v > 0 && v < float64(maxIter) {
+			band.lo = math.Min(band.lo, v)
+			band.hi = math.Max(band.hi, v)
+		}
+	}
+	return band
+}
+
+// logColor spreads the palette once over the frame's band of iteration
+// counts on a log scale, so deep zooms don't wrap it over a narrow band
+func logColor(v float64, maxIter int, palette []color.RGBA, band iterBand, offset float64, style blendStyle) color.RGBA {
+	if v <= 0 || v >= float64(maxIter) {
+		return color.RGBA{A: 255}
+	}
+
+	t := 0.0
+	if band.hi > band.lo {
+		t = math.Log1p(math.Max(v-band.lo, 0)) / math.Log1p(band.hi-band.lo)
+	}
+	return samplePalette(palette, t*float64(len(palette)-1)+offset, style)
+}
+
+// the potential is taken to this root before colouring, which spreads the
+// values close to 0 near the set across the palette
+const potentialRoot = 16
+
+// potentialColor colours an escaped point by its continuous potential
+// G = log|z_n| / 2^n. The smooth iteration count is n + 1 - log2(log|z_n|)
+// already, so G = 2^(1 - v) without going back to z. For powers other than 2
+// this is the potential's z^2 equivalent.
+func potentialColor(v float64, maxIter int, palette []color.RGBA, density, offset float64, style blendStyle) color.RGBA {
+	if v <= 0 || v >= float64(maxIter) {
+		return color.RGBA{A: 255}
+	}
+	potential := math.Exp2(1 - v)
+	t := 1 - math.Pow(potential/2, 1.0/potentialRoot)
+	return samplePalette(palette, t*float64(len(palette))*density+offset, style)
+}
+
+// distanceColor lights up points within a few pixels of the boundary, fading
+// to black further out. Inside the set is black too.
+func distanceColor(dist, pixelSize float64) color.RGBA {
+	if dist <= 0 {
+		return color.RGBA{A: 255}
+	}
+	t := math.Min(dist/(4*pixelSize), 1)
+	c := uint8(255 * (1 - math.Sqrt(t)))
+	return color.RGBA{c, c, c, 255}
+}
+
+// TrapShape is the shape orbits are measured against in orbit trap colouring
+type TrapShape int
+
+const (
+	TrapPoint  TrapShape = iota // the origin
+	TrapCross                   // the real and imaginary axes
+	TrapCircle                  // a circle of radius 0.5 around the origin
+
+	trapShapeCount // number of trap shapes, keep last
+)
+
+func (t TrapShape) String() string {
+	switch t {
+	case TrapPoint:
+		return "Point"
+	case TrapCross:
+		return "Cross"
+	case TrapCircle:
+		return "Circle"
+	default:
+		return "Unknown"
+	}
+}
+
+// distance from z = x + y*i to the trap
+func (t TrapShape) distance(x, y float64) float64 {
+	switch t {
+	case TrapCross:
+		return math.Min(math.Abs(x), math.Abs(y))
+	case TrapCircle:
+		return math.Abs(math.Hypot(x, y) - 0.5)
+	default:
+		return math.Hypot(x, y)
+	}
+}
+
+// trapColor sweeps the palette once over trap distances from 0 to 0.5
+func trapColor(dist float64, palette []color.RGBA, offset float64, style blendStyle) color.RGBA {
+	t := math.Min(dist*2, 1)
+	return samplePalette(palette, t*float64(len(palette)-1)+offset, style)
+}
+
+// interiorColor shades a point inside the set by shade, its final |z|^2/4,
+// with a darkened sweep of the palette
+func interiorColor(shade float64, palette []color.RGBA, style blendStyle) color.RGBA {
+	clr := samplePalette(palette, shade*float64(len(palette)-1), style)
+	return color.RGBA{clr.R / 2, clr.G / 2, clr.B / 2, 255}
+}
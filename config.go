@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+)
+
+const configPath = "config.json"
+
+// Config holds the startup defaults, any field left out of config.json keeps
+// its built-in value. Command line flags still override it.
+type Config struct {
+	CenterX   float64 `json:"centerX"`
+	CenterY   float64 `json:"centerY"`
+	Zoom      float64 `json:"zoom"`
+	ZoomSpeed float64 `json:"zoomSpeed"`
+	Width     int     `json:"width"`
+	Height    int     `json:"height"`
+	Palette   string  `json:"palette"`   // name of a built-in palette, or a CSV file like -palette
+	Crossfade bool    `json:"crossfade"` // fade between fractals when switching
+
+	TourSeconds float64 `json:"tourSeconds"` // length of each dive in and out of a tour stop
+}
+
+func defaultConfig() Config {
+	return Config{
+		CenterX:   defaultCenterX,
+		CenterY:   defaultCenterY,
+		Zoom:      defaultZoom,
+		ZoomSpeed: defaultZoomSpeed,
+		Width:     screenWidth,
+		Height:    screenHeight,
+		Palette:   palettes[0].Name,
+		Crossfade: true,
+
+		TourSeconds: 8,
+	}
+}
+
+// LoadConfig reads the startup defaults from path. A missing file gives the
+// built-in defaults and is not an error.
+func LoadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return defaultConfig(), fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// finite reports whether v is neither infinite nor NaN
+func finite(v float64) bool {
+	return !math.IsInf(v, 0) && !math.IsNaN(v)
+}
+
+func (c Config) validate() error {
+	switch {
+	case !finite(c.CenterX) || !finite(c.CenterY):
+		return fmt.Errorf("center must be finite")
+	case !finite(c.Zoom) || c.Zoom < 1:
+		return fmt.Errorf("zoom must be a finite number of at least 1, got %v", c.Zoom)
+	case !finite(c.ZoomSpeed) || math.Abs(c.ZoomSpeed) > maxZoomSpeed:
+		return fmt.Errorf("zoom speed must be between %v and %v, got %v", -maxZoomSpeed, maxZoomSpeed, c.ZoomSpeed)
+	case !finite(c.TourSeconds) || c.TourSeconds <= 0:
+		return fmt.Errorf("tour seconds must be positive, got %v", c.TourSeconds)
+	case c.Width <= sidebarWidth || c.Height <= 0:
+		return fmt.Errorf("window size %dx%d too small", c.Width, c.Height)
+	}
+	return nil
+}
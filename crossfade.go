@@ -0,0 +1,63 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// switching fractals fades from the old one to the new over crossfadeDuration seconds
+const crossfadeDuration = 0.5
+
+// fractalFade is the frame shown before a switch, fading out over the new fractal
+type fractalFade struct {
+	from *ebiten.Image
+	t    float64 // progress from 0 to 1
+}
+
+// startFade begins fading out the frame last shown. The copy being faded is
+// swapped out so the next frames can't overwrite it.
+func (g *Game) startFade() {
+	if !g.crossfade || g.shown == nil {
+		return
+	}
+	from := g.shown
+	g.shown = nil
+	if g.fade != nil {
+		g.shown = g.fade.from
+	}
+	g.fade = &fractalFade{from: from}
+}
+
+func (g *Game) updateFade(elapsed float64) {
+	f := g.fade
+	if f == nil {
+		return
+	}
+	f.t += elapsed / crossfadeDuration
+	if f.t >= 1 {
+		g.fade = nil
+	}
+}
+
+// drawFade blends the old frame over the w*h view, then keeps a copy of the
+// result for the next switch to fade from
+func (g *Game) drawFade(screen *ebiten.Image, w, h int) {
+	if !g.crossfade {
+		return
+	}
+	if f := g.fade; f != nil && f.from.Bounds().Dx() == w && f.from.Bounds().Dy() == h {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(sidebarWidth, 0)
+		op.ColorScale.ScaleAlpha(float32(1 - f.t))
+		screen.DrawImage(f.from, op)
+	}
+
+	if g.shown == nil || g.shown.Bounds().Dx() != w || g.shown.Bounds().Dy() != h {
+		g.shown = ebiten.NewImage(w, h)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.Blend = ebiten.BlendCopy
+	view := screen.SubImage(image.Rect(sidebarWidth, 0, sidebarWidth+w, h)).(*ebiten.Image)
+	g.shown.DrawImage(view, op)
+}
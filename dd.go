@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// past this zoom float64 runs out of bits to tell neighbouring pixels apart,
+// and the Mandelbrot switches to double-double arithmetic
+const ddZoom = 1e13
+
+// dd is a double-double number, the unevaluated sum hi + lo of two float64s
+// with |lo| below half an ulp of hi. That gives about 106 bits of mantissa.
+type dd struct {
+	hi, lo float64
+}
+
+// twoSum returns a + b exactly, as the rounded sum and its error
+func twoSum(a, b float64) dd {
+	s := a + b
+	v := s - a
+	return dd{s, (a - (s - v)) + (b - v)}
+}
+
+// quickTwoSum is twoSum for |a| >= |b|
+func quickTwoSum(a, b float64) dd {
+	s := a + b
+	return dd{s, b - (s - a)}
+}
+
+// twoProd returns a * b exactly, as the rounded product and its error
+func twoProd(a, b float64) dd {
+	p := a * b
+	return dd{p, math.FMA(a, b, -p)}
+}
+
+func (a dd) add(b dd) dd {
+	s := twoSum(a.hi, b.hi)
+	t := twoSum(a.lo, b.lo)
+	s.lo += t.hi
+	s = quickTwoSum(s.hi, s.lo)
+	s.lo += t.lo
+	return quickTwoSum(s.hi, s.lo)
+}
+
+func (a dd) sub(b dd) dd {
+	return a.add(dd{-b.hi, -b.lo})
+}
+
+func (a dd) mul(b dd) dd {
+	p := twoProd(a.hi, b.hi)
+	p.lo += a.hi*b.lo + a.lo*b.hi
+	return quickTwoSum(p.hi, p.lo)
+}
+
+// useDD reports whether the current view is too deep for float64 but not
+// deep enough to need perturbation
+func (g *Game) useDD() bool {
+	return g.fractalType == FractalMandelbrot && g.power == 2 && g.zoom > ddZoom && !g.useBigFloat()
+}
+
+// String writes a with the 32 significant digits a double-double holds
+func (a dd) String() string {
+	f := new(big.Float).SetPrec(256).SetFloat64(a.hi)
+	return f.Add(f, big.NewFloat(a.lo)).Text('g', 32)
+}
+
+// parseDD reads a decimal number to double-double precision
+func parseDD(s string) (dd, error) {
+	f, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return dd{}, err
+	}
+	hi, _ := f.Float64()
+	if f.IsInf() || math.IsInf(hi, 0) {
+		return dd{}, fmt.Errorf("%s is not a finite number", s)
+	}
+	lo, _ := f.Sub(f, big.NewFloat(hi)).Float64()
+	return dd{hi, lo}, nil
+}
+
+// preciseCenter is the view center in double-double. centerX and centerY are
+// its hi parts, rounded to float64 for everything but the deep zoom kernels.
+func (g *Game) preciseCenter() (x, y dd) {
+	return dd{g.centerX, g.centerLoX}, dd{g.centerY, g.centerLoY}
+}
+
+func (g *Game) setPreciseCenter(x, y dd) {
+	g.centerX, g.centerLoX = x.hi, x.lo
+	g.centerY, g.centerLoY = y.hi, y.lo
+}
+
+// setCenter moves the view center outright to a float64 point
+func (g *Game) setCenter(x, y float64) {
+	g.setPreciseCenter(dd{x, 0}, dd{y, 0})
+}
+
+// moveCenter shifts the view center by (dx, dy) in double-double, so the
+// moves of a deep zoom still add up once they are smaller than a float64 step
+func (g *Game) moveCenter(dx, dy float64) {
+	x, y := g.preciseCenter()
+	g.setPreciseCenter(x.add(dd{dx, 0}), y.add(dd{dy, 0}))
+}
+
+// ddSampler renders the Mandelbrot in double-double, every pixel's offset
+// from the precise center keeping its full precision
+func (g *Game) ddSampler(w, h, maxIter int) func(x, y int) float64 {
+	width, height := g.viewExtent()
+	cx, cy := g.preciseCenter()
+
+	return func(x, y int) float64 {
+		dx := width * (float64(x)/float64(w) - 0.5)
+		dy := height * (float64(y)/float64(h) - 0.5)
+		return mandelbrotDD(cx.add(dd{dx, 0}), cy.add(dd{dy, 0}), g.escapeRadius, maxIter)
+	}
+}
+
+// mandelbrotDD is mandelbrot in double-double
+func mandelbrotDD(cx, cy dd, escapeRadius float64, maxIter int) float64 {
+	var x, y dd
+	iteration := 0
+	bailout := escapeRadius * escapeRadius
+
+	for iteration < maxIter {
+		x2, y2 := x.mul(x), y.mul(y)
+		if x2.hi+y2.hi > bailout {
+			break
+		}
+		xy := x.mul(y)
+		y = xy.add(xy).add(cy)
+		x = x2.sub(y2).add(cx)
+		iteration++
+	}
+
+	if iteration < maxIter {
+		return smoothIter(iteration, x.hi*x.hi+y.hi*y.hi, escapeRadius, 2)
+	}
+	return interiorValue(x.hi, y.hi, maxIter)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// exact is a dd as an exact big.Float
+func exact(a dd) *big.Float {
+	x := new(big.Float).SetPrec(256).SetFloat64(a.hi)
+	return x.Add(x, new(big.Float).SetFloat64(a.lo))
+}
+
+// checkDD fails unless got is within a few ulps of double-double precision of want
+func checkDD(t *testing.T, name string, got dd, want *big.Float) {
+	t.Helper()
+	diff := new(big.Float).SetPrec(256).Sub(exact(got), want)
+	tolerance := new(big.Float).SetPrec(256).Abs(want)
+	tolerance.Mul(tolerance, big.NewFloat(0x1p-100))
+	if diff.Abs(diff).Cmp(tolerance) > 0 {
+		t.Errorf("%s = %v + %v, off by %v", name, got.hi, got.lo, diff)
+	}
+	if math.Abs(got.lo) > math.Abs(got.hi)*0x1p-52 {
+		t.Errorf("%s = %v + %v is not normalised", name, got.hi, got.lo)
+	}
+}
+
+var ddTests = []struct {
+	name string
+	a, b dd
+}{
+	{"whole numbers", dd{3, 0}, dd{5, 0}},
+	{"tiny lo parts", dd{1, 1e-20}, dd{2, -3e-21}},
+	{"near cancellation", dd{1, 1e-17}, dd{-1, 3e-18}},
+	{"different scales", dd{1e10, 1e-8}, dd{1e-10, 1e-28}},
+	{"thirds", twoSum(1.0/3, 1.0/3*0x1p-54), dd{-2.0 / 3, 0}},
+	{"negative", dd{-0.7453, 1.234e-18}, dd{-0.1127, -5.6e-19}},
+}
+
+func TestDDAdd(t *testing.T) {
+	for _, tt := range ddTests {
+		want := new(big.Float).SetPrec(256).Add(exact(tt.a), exact(tt.b))
+		checkDD(t, tt.name+": add", tt.a.add(tt.b), want)
+	}
+}
+
+func TestDDSub(t *testing.T) {
+	for _, tt := range ddTests {
+		want := new(big.Float).SetPrec(256).Sub(exact(tt.a), exact(tt.b))
+		checkDD(t, tt.name+": sub", tt.a.sub(tt.b), want)
+	}
+}
+
+func TestDDMul(t *testing.T) {
+	for _, tt := range ddTests {
+		want := new(big.Float).SetPrec(256).Mul(exact(tt.a), exact(tt.b))
+		checkDD(t, tt.name+": mul", tt.a.mul(tt.b), want)
+	}
+}
+
+func TestDDSquare(t *testing.T) {
+	for _, tt := range ddTests {
+		want := new(big.Float).SetPrec(256).Mul(exact(tt.a), exact(tt.a))
+		checkDD(t, tt.name+": square", tt.a.mul(tt.a), want)
+	}
+}
+
+func TestMandelbrotDDMatchesFloat64(t *testing.T) {
+	const maxIter = 500
+	points := []struct{ x, y float64 }{
+		{0, 0},
+		{-1, 0},
+		{0.26, 0},
+		{-0.75, 0.1},
+		{-0.7453, 0.1127},
+		{0.3, 0.5},
+		{-1.8, 0.01},
+		{2, 2},
+	}
+	for _, radius := range []float64{2, 16} {
+		for _, p := range points {
+			got := mandelbrotDD(dd{p.x, 0}, dd{p.y, 0}, radius, maxIter)
+			want := multibrot(p.x, p.y, 2, radius, maxIter)
+			// float64 stops early on interior points it sees cycling, so only
+			// escaped counts are compared
+			if (got >= maxIter) != (want >= maxIter) {
+				t.Errorf("(%v, %v) radius %v: mandelbrotDD = %v, multibrot = %v", p.x, p.y, radius, got, want)
+				continue
+			}
+			if want < maxIter && math.Abs(got-want) > 1e-6 {
+				t.Errorf("(%v, %v) radius %v: mandelbrotDD = %v, multibrot = %v", p.x, p.y, radius, got, want)
+			}
+		}
+	}
+}
+
+func TestPerturbationMatchesFloat64(t *testing.T) {
+	// at a shallow zoom the float64 kernel is exact enough to check perturbation against
+	g := testGame()
+	g.centerX, g.centerY = -0.7453, 0.1127
+	g.zoom = 1e6
+	const w, h, maxIter = 16, 12, 500
+	for _, radius := range []float64{2, 16} {
+		g.escapeRadius = radius
+		perturbed := g.perturbationSampler(w, h, maxIter)
+		float := g.pixelSampler(w, h, maxIter)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				a, b := perturbed(x, y), float(x, y)
+				if (a >= maxIter) != (b >= maxIter) || b < maxIter && math.Abs(a-b) > 1e-3 {
+					t.Errorf("radius %v pixel (%d, %d): perturbation = %v, float64 = %v", radius, x, y, a, b)
+				}
+			}
+		}
+	}
+}
+
+func TestDDSamplerMatchesFloat64(t *testing.T) {
+	// past ddZoom the double-double sampler takes over, just below it the
+	// float64 one still resolves the pixels, so both should agree there
+	g := testGame()
+	g.centerX, g.centerY = -0.7453, 0.1127
+	g.zoom = ddZoom / 10
+	const w, h = 16, 12
+	ddSample := g.ddSampler(w, h, 500)
+	floatSample := g.pixelSampler(w, h, 500)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a, b := ddSample(x, y), floatSample(x, y)
+			if (a >= 500) != (b >= 500) || b < 500 && math.Abs(a-b) > 1e-3 {
+				t.Errorf("pixel (%d, %d): ddSampler = %v, float64 = %v", x, y, a, b)
+			}
+		}
+	}
+}
+
+func TestMoveCenterKeepsTinyMoves(t *testing.T) {
+	// a thousand moves each far below a float64 step of the center add up to
+	// one that float64 can see
+	g := testGame()
+	g.setCenter(-0.75, 0.1)
+	step := 0x1p-60
+	for range 1000 {
+		g.moveCenter(step, -step)
+	}
+	x, y := g.preciseCenter()
+	checkDD(t, "center x", x, new(big.Float).SetPrec(256).Add(big.NewFloat(-0.75), big.NewFloat(1000*step)))
+	checkDD(t, "center y", y, new(big.Float).SetPrec(256).Sub(big.NewFloat(0.1), big.NewFloat(1000*step)))
+	if g.centerX == -0.75 {
+		t.Errorf("centerX did not move")
+	}
+}
+
+func TestParseCenterRoundTrip(t *testing.T) {
+	x, y := twoSum(-0.7436438870371587, 1.4e-27), twoSum(0.13182590420531198, -3.1e-26)
+	px, py, err := parseCenter(x.String() + ", " + y.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDD(t, "x", px, exact(x))
+	checkDD(t, "y", py, exact(y))
+
+	for _, s := range []string{"1", "1,Inf", "NaN,0", "1e400,0", "a,b"} {
+		if _, _, err := parseCenter(s); err == nil {
+			t.Errorf("parseCenter(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// past this zoom even double-double runs out of bits, and the Mandelbrot
+// switches to perturbation against a math/big reference orbit
+const bigFloatZoom = 1e28
+
+// useBigFloat reports whether the current view needs arbitrary precision.
+// Only the standard Mandelbrot has a high precision kernel.
+func (g *Game) useBigFloat() bool {
+	return g.fractalType == FractalMandelbrot && g.power == 2 && g.zoom > bigFloatZoom
+}
+
+// bigFloatPrec returns the mantissa bits needed to resolve pixels at zoom:
+// float64's 53 bits plus one for every doubling of the zoom, with some headroom
+func bigFloatPrec(zoom float64) int {
+	return 64 + int(math.Ceil(math.Log2(math.Max(zoom, 1))))
+}
+
+func (g *Game) precisionName() string {
+	if g.useGPU() {
+		return "float32 (GPU)"
+	}
+	if g.useBigFloat() {
+		return fmt.Sprintf("perturbation (%d bits)", bigFloatPrec(g.zoom))
+	}
+	if g.useDD() {
+		return "double-double"
+	}
+	return "float64"
+}
+
+// pixels closer together than this many steps of the float in use come out as blocks
+const precisionMargin = 2
+
+// precisionLost reports whether the arithmetic in use can no longer tell
+// neighbouring pixels apart, which perturbation never runs into since it
+// takes more bits the deeper it goes
+func (g *Game) precisionLost() bool {
+	if g.useBigFloat() {
+		return false
+	}
+	eps := 0x1p-52
+	if g.useDD() {
+		eps = 0x1p-104
+	}
+	width, _ := g.viewExtent()
+	w, _ := g.viewSize()
+	scale := math.Max(math.Max(math.Abs(g.centerX), math.Abs(g.centerY)), 1)
+	return width/float64(w) < eps*scale*precisionMargin
+}
+
+// perturbationSampler renders the Mandelbrot relative to one high precision
+// reference orbit at the view center. Each pixel only tracks its float64
+// offset from that orbit, which stays small enough to be accurate.
+func (g *Game) perturbationSampler(w, h, maxIter int) func(x, y int) float64 {
+	prec := uint(bigFloatPrec(g.zoom))
+	cx := new(big.Float).SetPrec(prec).SetFloat64(g.centerX)
+	cx.Add(cx, new(big.Float).SetFloat64(g.centerLoX))
+	cy := new(big.Float).SetPrec(prec).SetFloat64(g.centerY)
+	cy.Add(cy, new(big.Float).SetFloat64(g.centerLoY))
+	orbit := referenceOrbit(cx, cy, g.escapeRadius, maxIter)
+
+	width, height := g.viewExtent()
+
+	return func(x, y int) float64 {
+		dc := complex(width*(float64(x)/float64(w)-0.5), height*(float64(y)/float64(h)-0.5))
+		return perturbedMandelbrot(orbit, dc, g.escapeRadius, maxIter)
+	}
+}
+
+// referenceOrbit iterates z -> z^2 + c at the precision of cx, returning the
+// orbit up to and including the first escaped point (or maxIter steps)
+func referenceOrbit(cx, cy *big.Float, escapeRadius float64, maxIter int) []complex128 {
+	prec := cx.Prec()
+	newFloat := func() *big.Float { return new(big.Float).SetPrec(prec) }
+	x, y := newFloat(), newFloat()
+	x2, y2, xy := newFloat(), newFloat(), newFloat()
+	mag := newFloat()
+	bailout := big.NewFloat(escapeRadius * escapeRadius)
+
+	orbit := make([]complex128, 1, maxIter+1)
+	for iteration := 0; iteration < maxIter; iteration++ {
+		x2.Mul(x, x)
+		y2.Mul(y, y)
+		xy.Mul(x, y)
+		y.Add(xy, xy)
+		y.Add(y, cy)
+		x.Sub(x2, y2)
+		x.Add(x, cx)
+
+		xf, _ := x.Float64()
+		yf, _ := y.Float64()
+		orbit = append(orbit, complex(xf, yf))
+
+		x2.Mul(x, x)
+		y2.Mul(y, y)
+		if mag.Add(x2, y2).Cmp(bailout) > 0 {
+			break
+		}
+	}
+	return orbit
+}
+
+// perturbedMandelbrot iterates the offset dz of a pixel from the reference
+// orbit, dz -> 2*Z*dz + dz^2 + dc. When the pixel's orbit gets closer to zero
+// than its offset, or the reference runs out, dz is rebased onto the start of
+// the reference orbit so it never grows large enough to lose precision.
+func perturbedMandelbrot(orbit []complex128, dc complex128, escapeRadius float64, maxIter int) float64 {
+	dz := complex(0, 0)
+	ref := 0
+	bailout := escapeRadius * escapeRadius
+
+	for iteration := 0; iteration < maxIter; iteration++ {
+		z := orbit[ref] + dz
+		mag := real(z)*real(z) + imag(z)*imag(z)
+		if mag > bailout {
+			return smoothIter(iteration, mag, escapeRadius, 2)
+		}
+
+		if mag < real(dz)*real(dz)+imag(dz)*imag(dz) || ref == len(orbit)-1 {
+			dz = z
+			ref = 0
+		}
+
+		dz = 2*orbit[ref]*dz + dz*dz + dc
+		ref++
+	}
+
+	z := orbit[ref] + dz
+	return interiorValue(real(z), imag(z), maxIter)
+}
@@ -0,0 +1,80 @@
+package main
+
+import "math"
+
+// a click zooms in by diveZoom over diveDuration seconds
+const (
+	diveZoom     = 4
+	diveDuration = 1.0
+)
+
+// presses that move less than this many pixels count as clicks rather than drags
+const clickSlop = 3
+
+// zoomDive animates the view from one center and zoom to another. The move is
+// kept as an offset from the precise starting center, so dives deep in a zoom
+// land where they were aimed.
+type zoomDive struct {
+	fromX, fromY     dd
+	dx, dy           float64 // from the starting center to the target
+	fromZoom, toZoom float64
+	duration         float64 // seconds
+	t                float64 // progress from 0 to 1
+}
+
+// startDive begins a smooth zoom into the point (dx, dy) away from the center
+func (g *Game) startDive(dx, dy float64) {
+	g.diveBy(dx, dy, math.Min(g.zoom*diveZoom, maxZoom), diveDuration)
+}
+
+// diveTo begins a smooth move to center (x, y) at zoom, over duration seconds
+func (g *Game) diveTo(x, y, zoom, duration float64) {
+	cx, cy := g.preciseCenter()
+	g.diveBy(dd{x, 0}.sub(cx).hi, dd{y, 0}.sub(cy).hi, zoom, duration)
+}
+
+// diveBy is diveTo for a target given as its offset from the center
+func (g *Game) diveBy(dx, dy, zoom, duration float64) {
+	fromX, fromY := g.preciseCenter()
+	g.dive = &zoomDive{
+		fromX: fromX, fromY: fromY, dx: dx, dy: dy,
+		fromZoom: g.zoom, toZoom: zoom,
+		duration: duration,
+	}
+}
+
+// updateDive moves the view along the dive. The zoom is interpolated
+// geometrically so it feels as fast at the end as at the start, and the
+// whole move eases in and out.
+func (g *Game) updateDive(elapsed float64) {
+	d := g.dive
+	if d == nil {
+		return
+	}
+	d.t = math.Min(d.t+elapsed/d.duration, 1)
+	s := d.t * d.t * (3 - 2*d.t)
+
+	g.zoom = d.fromZoom * math.Pow(d.toZoom/d.fromZoom, s)
+	// the center covers the same share of its path as the view has narrowed,
+	// so the target drifts steadily to the middle while the zoom speeds up.
+	// Zooming out runs the same path backwards.
+	f := s
+	if d.toZoom > d.fromZoom {
+		f = (1 - d.fromZoom/g.zoom) / (1 - d.fromZoom/d.toZoom)
+	} else if d.toZoom < d.fromZoom {
+		f = 1 - (1-d.toZoom/g.zoom)/(1-d.toZoom/d.fromZoom)
+	}
+	g.setPreciseCenter(d.fromX, d.fromY)
+	g.moveCenter(d.dx*f, d.dy*f)
+
+	if d.t == 1 {
+		g.dive = nil
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// resolution used by the high resolution export
+const (
+	exportWidth  = 3840
+	exportHeight = 2160
+)
+
+// exportJob tracks an export rendering in the background
+type exportJob struct {
+	rows  atomic.Int64 // rows of samples computed so far
+	total int64
+	done  atomic.Bool
+}
+
+// fraction is how much of the render is done, from 0 to 1
+func (j *exportJob) fraction() float64 {
+	return math.Min(float64(j.rows.Load())/float64(j.total), 1)
+}
+
+// renderToImage renders the current view at w*h, independent of the window
+// size. It is centered and zoomed as on screen and covers at least what the
+// window shows, with more of the plane on the sides a different aspect ratio
+// leaves. g should be a copy of the game, it is left set to draw at w*h.
+func renderToImage(g *Game, w, h int) *image.RGBA {
+	g.exportW, g.exportH = w, h
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	g.renderFractal(img.Pix, w, h)
+	return img
+}
+
+// saveScreenshot saves the current view at window resolution, without the sidebar or overlay
+func (g *Game) saveScreenshot() {
+	w, h := g.viewSize()
+	g.exportView("fractal-"+time.Now().Format("20060102-150405"), w, h)
+}
+
+// saveHighRes saves the current view at the export resolution
+func (g *Game) saveHighRes() {
+	name := fmt.Sprintf("fractal-%s-%dx%d", time.Now().Format("20060102-150405"), exportWidth, exportHeight)
+	g.exportView(name, exportWidth, exportHeight)
+}
+
+// exportView renders a snapshot of the view to base.png, with the view parameters
+// in a base.txt sidecar. Rendering and encoding happen in the background so the
+// render loop is not held up.
+func (g *Game) exportView(base string, w, h int) {
+	job := &exportJob{total: int64(h * g.aaSamples)}
+	g.export = job
+
+	view := *g
+	view.pixels = nil
+	view.rowsDone = &job.rows
+	view.background = true
+	x, y := g.preciseCenter()
+	info := fmt.Sprintf("type=%s\ncenter=(%v, %v)\nzoom=%g\n", fractalName(g.fractalType), x, y, g.zoom)
+
+	go func() {
+		defer job.done.Store(true)
+
+		img := renderToImage(&view, w, h)
+		if err := writePNG(base+".png", img); err != nil {
+			log.Printf("export: %v", err)
+			return
+		}
+		if err := os.WriteFile(base+".txt", []byte(info), 0644); err != nil {
+			log.Printf("export: %v", err)
+			return
+		}
+		log.Printf("saved %s.png", base)
+	}()
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"log"
+	"math"
+	"os"
+	"sync/atomic"
+)
+
+// zoom animation settings
+const (
+	gifPath          = "zoom.gif"
+	gifFrames        = 60
+	gifDelay         = 5    // hundredths of a second per frame
+	gifZoomPerFrame  = 1.05 // zoom factor between frames
+	gifPaletteColors = 255  // leaves one entry for the black interior
+)
+
+// gifRecording tracks a zoom animation being rendered in the background
+type gifRecording struct {
+	frames atomic.Int32 // frames rendered so far
+	done   atomic.Bool
+}
+
+// recordGIF renders gifFrames frames zooming into the current center and
+// writes them to zoom.gif. It runs in the background on a snapshot of the
+// view; g.gif reports its progress until it finishes.
+func (g *Game) recordGIF() {
+	if g.gif != nil {
+		return
+	}
+	rec := &gifRecording{}
+	g.gif = rec
+
+	view := *g
+	view.pixels, view.values = nil, nil
+	view.background = true
+	colors := gifPalette(g.palette())
+	w, h := g.viewSize()
+
+	go func() {
+		defer rec.done.Store(true)
+
+		anim := &gif.GIF{}
+		startZoom := view.zoom
+		for i := 0; i < gifFrames; i++ {
+			view.zoom = math.Min(startZoom*math.Pow(gifZoomPerFrame, float64(i)), maxZoom)
+			img := renderToImage(&view, w, h)
+
+			frame := image.NewPaletted(img.Bounds(), colors)
+			draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+			anim.Image = append(anim.Image, frame)
+			anim.Delay = append(anim.Delay, gifDelay)
+			rec.frames.Add(1)
+		}
+
+		if err := writeGIF(gifPath, anim); err != nil {
+			log.Printf("gif: %v", err)
+			return
+		}
+		log.Printf("saved %s", gifPath)
+	}()
+}
+
+// gifPalette fits the palette into a gif's 256 colours, black for the
+// interior plus evenly spaced stops along the palette's gradient
+func gifPalette(palette []color.RGBA) color.Palette {
+	colors := color.Palette{color.RGBA{A: 255}}
+	for i := 0; i < gifPaletteColors; i++ {
+		colors = append(colors, samplePalette(palette, float64(i)*float64(len(palette))/gifPaletteColors, blendStyle{}))
+	}
+	return colors
+}
+
+func writeGIF(path string, anim *gif.GIF) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
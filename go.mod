@@ -4,6 +4,7 @@ go 1.23.1
 
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.7.8
+	golang.design/x/clipboard v0.7.0
 	golang.org/x/image v0.18.0
 )
 
@@ -12,6 +13,8 @@ require (
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.7.0 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
+	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
+	golang.org/x/mobile v0.0.0-20230301163155-e0f57694e12c // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 )
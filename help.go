@@ -0,0 +1,92 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/basicfont"
+)
+
+// keyBinding is one line of the help overlay
+type keyBinding struct {
+	keys, action string
+}
+
+// keyBindings lists every mouse action and key that Update handles, add to it
+// alongside any new binding. help_test.go checks its keys against the ones the
+// source handles.
+var keyBindings = []keyBinding{
+	{"Drag", "pan"},
+	{"Click", "dive into the point"},
+	{"Scroll", "zoom at the cursor"},
+	{"Middle click", "recenter on the cursor"},
+	{"Right click", "julia set of the point"},
+	{"Minimap click", "jump there"},
+	{"Arrows", "pan"},
+	{"+ / -", "zoom in / out"},
+	{"Shift+scroll", "nudge the julia constant"},
+	{"Shift+arrows", "nudge the julia constant"},
+	{"Space", "pause"},
+	{"T / Shift+T", "next / previous fractal"},
+	{"V", "default view"},
+	{"Enter", "guided tour"},
+	{"F", "snap to the next minibrot"},
+	{"D", "julia picker"},
+	{"J", "animate the julia constant"},
+	{"X", "transcendental function"},
+	{"[ / ]", "power, or mandelbox scale"},
+	{"; / '", "escape radius"},
+	{"Ctrl+Z / Ctrl+Y", "undo / redo"},
+	{"P", "next palette"},
+	{"M", "colouring mode"},
+	{"O", "orbit trap shape"},
+	{"I / Shift+I", "interior by |z| / settling"},
+	{"K / Shift+K", "colour cycle / its speed"},
+	{", / .", "gamma"},
+	{"< / >", "colour density"},
+	{"L", "blend colour space"},
+	{"W", "dither"},
+	{"F2", "bulb period"},
+	{"F3", "iteration heatmap"},
+	{"F4", "edge line art"},
+	{"F6", "biomorphs"},
+	{"Q", "quality preset"},
+	{"A", "anti-aliasing"},
+	{"U", "GPU rendering"},
+	{"R", "fast preview pixel step"},
+	{"Tab", "banded rendering"},
+	{"F7 / F8", "update rate / vsync"},
+	{"F10", "accumulate a still view"},
+	{"S", "screenshot"},
+	{"E", "high resolution export"},
+	{"G", "record a zoom GIF"},
+	{"C", "copy the view"},
+	{"B / N", "add / next bookmark"},
+	{"F5 / F9", "save / load the scene"},
+	{"H", "this help"},
+}
+
+// help overlay layout, in pixels
+const (
+	helpMargin     = 20
+	helpLineHeight = 15
+	helpKeysWidth  = 110 // the action column starts this far right of the keys
+	helpColumnGap  = 300
+)
+
+// drawHelp dims the whole window and lists keyBindings over it, in as many
+// columns as the window's height needs
+func drawHelp(screen *ebiten.Image, g *Game) {
+	vector.DrawFilledRect(screen, 0, 0, float32(g.width), float32(g.height), color.RGBA{A: 200}, false)
+
+	myFont := basicfont.Face7x13
+	rows := max((g.height-2*helpMargin)/helpLineHeight, 1)
+	for i, b := range keyBindings {
+		x := helpMargin + i/rows*helpColumnGap
+		y := helpMargin + 10 + i%rows*helpLineHeight
+		text.Draw(screen, b.keys, myFont, x, y, color.RGBA{255, 255, 0, 255})
+		text.Draw(screen, b.action, myFont, x+helpKeysWidth, y, color.White)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// helpLabels maps ebiten key names to how keyBindings writes them, for the
+// keys whose label isn't just the name without its Key prefix
+var helpLabels = map[string]string{
+	"ArrowLeft":      "Arrows",
+	"ArrowRight":     "Arrows",
+	"ArrowUp":        "Arrows",
+	"ArrowDown":      "Arrows",
+	"Equal":          "+",
+	"NumpadAdd":      "+",
+	"Minus":          "-",
+	"NumpadSubtract": "-",
+	"BracketLeft":    "[",
+	"BracketRight":   "]",
+	"Semicolon":      ";",
+	"Apostrophe":     "'",
+	"Comma":          ",",
+	"Period":         ".",
+}
+
+// shifted labels in keyBindings are the shifted keys of these
+var shiftedLabels = map[string]string{"<": ",", ">": "."}
+
+// modifiers only change what another key does, and have no lines of their own
+var modifierKeys = map[string]bool{"Shift": true, "Control": true}
+
+// mouse actions in keyBindings, which have no key
+var mouseLabels = map[string]bool{
+	"Drag": true, "Click": true, "Scroll": true,
+	"Middle click": true, "Right click": true, "Minimap click": true,
+}
+
+// usedKeys returns the help label of every ebiten key the game's source refers to
+func usedKeys(t *testing.T) map[string]bool {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	used := map[string]bool{}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "ebiten" || !strings.HasPrefix(sel.Sel.Name, "Key") {
+				return true
+			}
+			name := strings.TrimPrefix(sel.Sel.Name, "Key")
+			if modifierKeys[name] {
+				return true
+			}
+			if label, ok := helpLabels[name]; ok {
+				name = label
+			}
+			used[name] = true
+			return true
+		})
+	}
+	return used
+}
+
+// helpKeys returns every key label in keyBindings, without its modifiers
+func helpKeys() map[string]bool {
+	keys := map[string]bool{}
+	for _, b := range keyBindings {
+		for _, part := range strings.Split(b.keys, " / ") {
+			if part != "+" {
+				part = strings.TrimPrefix(part, "Shift+")
+				part = strings.TrimPrefix(part, "Ctrl+")
+			}
+			// after a modifier a name starts lower case, as in Shift+arrows
+			part = strings.ToUpper(part[:1]) + part[1:]
+			if mouseLabels[part] {
+				continue
+			}
+			if unshifted, ok := shiftedLabels[part]; ok {
+				part = unshifted
+			}
+			keys[part] = true
+		}
+	}
+	return keys
+}
+
+func TestHelpListsEveryKey(t *testing.T) {
+	help := helpKeys()
+	for key := range usedKeys(t) {
+		if !help[key] {
+			t.Errorf("key %s is handled but missing from keyBindings", key)
+		}
+	}
+}
+
+func TestHelpListsOnlyHandledKeys(t *testing.T) {
+	used := usedKeys(t)
+	for key := range helpKeys() {
+		if !used[key] {
+			t.Errorf("keyBindings lists %s, which nothing handles", key)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+// the oldest views are dropped past this many
+const historySize = 100
+
+// recordHistory pushes the view once it settles after a change, dropping any
+// views that were undone before it. Settling back onto the current entry,
+// like after an undo, pushes nothing.
+func (g *Game) recordHistory(view viewState, pixelSize float64, w int) {
+	if len(g.history) > 0 && !view.movedFrom(g.history[g.historyIndex], pixelSize, w) {
+		return
+	}
+	g.history = append(g.history[:min(g.historyIndex+1, len(g.history))], view)
+	if len(g.history) > historySize {
+		g.history = g.history[len(g.history)-historySize:]
+	}
+	g.historyIndex = len(g.history) - 1
+}
+
+// undo steps back to the previous settled view
+func (g *Game) undo() {
+	if g.historyIndex > 0 {
+		g.historyIndex--
+		g.applyView(g.history[g.historyIndex])
+	}
+}
+
+// redo steps forward again after an undo
+func (g *Game) redo() {
+	if g.historyIndex < len(g.history)-1 {
+		g.historyIndex++
+		g.applyView(g.history[g.historyIndex])
+	}
+}
+
+// applyView restores a view from the history. The iteration count is left
+// alone, when it is automatic it follows the restored zoom anyway.
+func (g *Game) applyView(v viewState) {
+	g.setPreciseCenter(dd{v.centerX, v.centerLoX}, dd{v.centerY, v.centerLoY})
+	g.zoom = v.zoom
+	if usesJuliaConstant(v.fractalType) {
+		g.juliaX, g.juliaY = v.juliaX, v.juliaY
+	}
+	g.fractalType = v.fractalType
+	g.transFunc = v.transFunc
+	g.power = v.power
+	g.boxScale = v.boxScale
+	g.escapeRadius = v.escapeRadius
+	g.animateJulia = false
+}
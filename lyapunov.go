@@ -0,0 +1,50 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// sequence of the two growth rates the logistic map alternates between
+const lyapunovSequence = "AB"
+
+// iterations run to let the orbit settle before the exponent is measured
+const lyapunovWarmup = 50
+
+// lyapunov is the Lyapunov exponent of the logistic map x -> r*x*(1-x),
+// with r taking the value a or b as seq says, A for a and B for b. Negative
+// exponents are stable orbits, positive ones chaos. Growth rates that throw
+// x out of [0, 1] give +Inf.
+func lyapunov(a, b float64, seq string, maxIter int) float64 {
+	x := 0.5
+	sum := 0.0
+	for i := 0; i < lyapunovWarmup+maxIter; i++ {
+		r := a
+		if seq[i%len(seq)] == 'B' {
+			r = b
+		}
+		x = r * x * (1 - x)
+		if x < 0 || x > 1 || math.IsNaN(x) {
+			return math.Inf(1)
+		}
+		if i >= lyapunovWarmup {
+			sum += math.Log(math.Abs(r * (1 - 2*x)))
+		}
+	}
+	return sum / float64(maxIter)
+}
+
+// lyapunovColor shades stable points blue and chaotic ones yellow, both
+// brighter the further the exponent is from zero. Diverging points are black.
+func lyapunovColor(exponent float64) color.RGBA {
+	switch {
+	case math.IsInf(exponent, 0) || math.IsNaN(exponent):
+		return color.RGBA{A: 255}
+	case exponent < 0:
+		c := uint8(255 * (1 - math.Exp(exponent)))
+		return color.RGBA{0, c / 3, c, 255}
+	default:
+		c := uint8(255 * math.Min(exponent/math.Ln2, 1))
+		return color.RGBA{c, c, 0, 255}
+	}
+}
@@ -1,178 +1,1996 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
+	"math/cmplx"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/font/basicfont"
 )
 
+// initial window size, the window can be resized from there
+const (
+	screenWidth  = 640
+	screenHeight = 480
+	sidebarWidth = 100
+)
+
+// width of the progress bars of background renders
+const progressWidth = 200
+
+// the status list starts at statusTop and runs down in statusLineHeight lines,
+// wrapping into columns statusColumnWidth apart once it reaches the bottom
+const (
+	statusTop         = 80
+	statusLineHeight  = 20
+	statusColumnWidth = 250
+)
+
+// sidebar sliders, shared by drawSidebar and the hit test in Update
+const (
+	sliderTop    = 70
+	sliderHeight = 200
+	sliderWidth  = 10
+	sliderGrab   = 20 // how far right of a slider a press still grabs it
+
+	zoomSliderX   = 10
+	maxZoomSpeed  = 0.5   // the zoom speed slider runs from maxZoomSpeed down to -maxZoomSpeed
+	zoomSpeedFine = 0.001 // scale of the fine, slow speeds around the slider's middle
+
+	iterSliderX   = 40
+	sliderMinIter = 50
+	sliderMaxIter = 2000
+)
+
+/*
+Default view, centered on Seahorse Valley
+http://www.mrob.com/pub/muency/seahorsevalley.html
+*/
+const (
+	defaultCenterX   = 0.42884
+	defaultCenterY   = -0.231345
+	defaultZoom      = 1.0
+	defaultZoomSpeed = 0.01
+)
+
+// the animated julia constant travels around a circle, radians per second
+const (
+	juliaPathRadius = 0.7885
+	juliaPathSpeed  = 0.5
+)
+
+// how far one nudge moves the julia constant
+const juliaStep = 0.001
+
+// how long the view has to stay still before rendering at full resolution again
+const settleTime = 200 * time.Millisecond
+
+// deepest zoom allowed, past ddZoom the Mandelbrot switches to double-double
+// and past bigFloatZoom to perturbation
+const maxZoom = 1e30
+
 const (
 	FractalMandelbrot = iota
 	FractalJulia
+	FractalBurningShip
+	FractalTricorn
+	FractalNewton
+	FractalPhoenix
+	FractalCeltic
+	FractalNova
+	FractalTranscendental // julia sets of c*sin(z), c*cos(z) and c*exp(z)
+	FractalBuffalo
+	FractalMagnet1
+	FractalCollatz
+	FractalLyapunov // the plane picks the two growth rates of the logistic map
+	FractalSpider
+	FractalGlynn // julia set of z^1.5 - 0.2
+	FractalPerpMandelbrot
+	FractalPerpBurningShip
+	FractalMandelbox // 2D slice of the box and ball folding fractal
+	FractalManowar
+	FractalHalley
 	// (wip) adding more fractals
+
+	fractalCount // number of fractal types, keep last
 )
 
-func mandelbrot(cx, cy float64, maxIter int) float64 {
-	x, y := 0.0, 0.0
-	iteration := 0
+// interiorValue is the value of a point that never escaped: maxIter plus
+// |z|^2/4 of its final z (below 1), which interior colouring shades by
+func interiorValue(x, y float64, maxIter int) float64 {
+	return float64(maxIter) + math.Min((x*x+y*y)/4, 0.999)
+}
+
+// smoothIter is the continuous iteration count of a point that escaped past
+// radius after n iterations of a z^power map, ending at |z|^2 = mag.
+// It is normalised so every radius lines up with the default radius of 2.
+func smoothIter(n int, mag, radius, power float64) float64 {
+	logZn := math.Log(mag) / 2
+	return float64(n) + 1 - math.Log(logZn*(math.Log(2)/math.Log(radius)))/math.Log(power)
+}
+
+// periodCheck spots an orbit that has fallen into a cycle, which can never
+// escape. It compares z against a saved point that moves up to the current z
+// after 8, 16, 32, ... iterations, so cycles of any length get caught.
+type periodCheck struct {
+	x, y       float64
+	iterations int
+	next       int
+	saved      int // iterations when the saved point was taken
+}
+
+// points of an orbit closer than this are taken to be the same
+const periodEpsilon = 1e-13
+
+// cycled reports whether z = x + y*i has returned to the saved point
+func (p *periodCheck) cycled(x, y float64) bool {
+	if p.iterations > 0 && math.Abs(x-p.x) < periodEpsilon && math.Abs(y-p.y) < periodEpsilon {
+		return true
+	}
+	p.iterations++
+	if p.iterations >= p.next {
+		p.x, p.y = x, y
+		p.saved = p.iterations
+		p.next = max(2*p.next, 8)
+	}
+	return false
+}
+
+// period is the length of the cycle once cycled has found it. The saved
+// point is already on the cycle, so its first return is one period later.
+func (p *periodCheck) period() int {
+	return p.iterations + 1 - p.saved
+}
+
+// orbits of the view center get this long to settle for the period readout,
+// near the edge of a bulb they settle slowly
+const periodSearchIter = 100000
+
+// attractorPeriod is the period of the cycle the Mandelbrot orbit of c
+// settles onto, 0 when it escapes or hasn't settled yet
+func attractorPeriod(cx, cy float64) int {
+	x, y := 0.0, 0.0
+	var period periodCheck
+	for iteration := 0; iteration < periodSearchIter && x*x+y*y <= 4; iteration++ {
+		x, y = x*x-y*y+cx, 2*x*y+cy
+		if period.cycled(x, y) {
+			return period.period()
+		}
+	}
+	return 0
+}
+
+func mandelbrot(cx, cy, escapeRadius float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+	bailout := escapeRadius * escapeRadius
+
+	var period periodCheck
+	for x*x+y*y <= bailout && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = 2*x*y + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		return smoothIter(iteration, x*x+y*y, escapeRadius, 2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// multibrot iterates z -> z^power + c, computing the power in polar form.
+// power 2 defers to mandelbrot so the standard set renders identically.
+func multibrot(cx, cy float64, power int, escapeRadius float64, maxIter int) float64 {
+	if power == 2 {
+		return mandelbrot(cx, cy, escapeRadius, maxIter)
+	}
+
+	x, y := 0.0, 0.0
+	iteration := 0
+	d := float64(power)
+	bailout := escapeRadius * escapeRadius
+
+	var period periodCheck
+	for x*x+y*y <= bailout && iteration < maxIter {
+		r := math.Pow(x*x+y*y, d/2)
+		theta := d * math.Atan2(y, x)
+		x = r*math.Cos(theta) + cx
+		y = r*math.Sin(theta) + cy
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		return smoothIter(iteration, x*x+y*y, escapeRadius, d)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// mandelbrotDE estimates the distance from c to the Mandelbrot set, tracking the
+// derivative dz alongside z. Points inside the set return 0.
+func mandelbrotDE(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	dx, dy := 0.0, 0.0
+	iteration := 0
+
+	// a large bailout keeps the estimate accurate
+	for x*x+y*y <= 1e6 && iteration < maxIter {
+		// dz = 2*z*dz + 1
+		dx, dy = 2*(x*dx-y*dy)+1, 2*(x*dy+y*dx)
+		xTemp := x*x - y*y + cx
+		y = 2*x*y + cy
+		x = xTemp
+		iteration++
+	}
+
+	if iteration < maxIter {
+		zn := math.Sqrt(x*x + y*y)
+		return zn * math.Log(zn) / math.Sqrt(dx*dx+dy*dy)
+	}
+	return 0
+}
+
+// mandelbrotOrbitTrap returns how close the orbit of c comes to the trap shape
+func mandelbrotOrbitTrap(cx, cy float64, maxIter int, trap TrapShape) float64 {
+	x, y := 0.0, 0.0
+	minDist := math.Inf(1)
+	iteration := 0
+
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = 2*x*y + cy
+		x = xTemp
+		iteration++
+
+		minDist = math.Min(minDist, trap.distance(x, y))
+	}
+	return minDist
+}
+
+func julia(x, y, cx, cy, escapeRadius float64, settle bool, maxIter int) float64 {
+	iteration := 0
+	bailout := escapeRadius * escapeRadius
+
+	var period periodCheck
+	for x*x+y*y <= bailout && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = 2*x*y + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			if settle {
+				return settleValue(iteration, maxIter)
+			}
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		return smoothIter(iteration, x*x+y*y, escapeRadius, 2)
+	}
+	if settle {
+		return settleValue(maxIter, maxIter)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// settleValue is the value of an interior point whose orbit took n
+// iterations to settle onto its cycle: maxIter plus a shade below 1 rising
+// with log n, so interior colouring glows brighter towards the boundary
+func settleValue(n, maxIter int) float64 {
+	return float64(maxIter) + math.Min(math.Log1p(float64(n))/math.Log1p(float64(maxIter)), 0.999)
+}
+
+// the Glynn fractal is the julia set of z^glynnPower + c for this c
+const (
+	glynnPower = 1.5
+	glynnX     = -0.2
+	glynnY     = 0.0
+)
+
+// glynn is julia for a fractional power, taking z^power in polar form on the
+// principal branch
+func glynn(x, y, cx, cy, power float64, maxIter int) float64 {
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		r := math.Pow(x*x+y*y, power/2)
+		theta := power * math.Atan2(y, x)
+		x = r*math.Cos(theta) + cx
+		y = r*math.Sin(theta) + cy
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		return smoothIter(iteration, x*x+y*y, 2, power)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+func burningShip(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = 2*math.Abs(x*y) + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// tricorn (mandelbar) conjugates z before each squaring step
+func tricorn(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = -2*x*y + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// perpMandelbrot is the tricorn with only the real part of z folded to positive
+func perpMandelbrot(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = -2*math.Abs(x)*y + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// perpBurningShip is the tricorn with only the imaginary part of z folded to positive
+func perpBurningShip(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = -2*x*math.Abs(y) + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// phoenixP weights the previous z in the phoenix iteration
+const phoenixP = -0.5
+
+// phoenix iterates z -> z^2 + c + p*zPrev, carrying the previous z along
+func phoenix(cx, cy, p float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	xPrev, yPrev := 0.0, 0.0
+	iteration := 0
+
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xNew := x*x - y*y + cx + p*xPrev
+		yNew := 2*x*y + cy + p*yPrev
+		xPrev, yPrev = x, y
+		x, y = xNew, yNew
+		iteration++
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// manowar iterates z -> z^2 + zPrev + c, like phoenix with the previous z
+// added in whole, and both start at c
+func manowar(cx, cy float64, maxIter int) float64 {
+	x, y := cx, cy
+	xPrev, yPrev := cx, cy
+	iteration := 0
+
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xNew := x*x - y*y + xPrev + cx
+		yNew := 2*x*y + yPrev + cy
+		xPrev, yPrev = x, y
+		x, y = xNew, yNew
+		iteration++
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// celtic takes the absolute value of the real part of z^2 before adding c
+func celtic(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := math.Abs(x*x-y*y) + cx
+		y = 2*x*y + cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// buffalo folds both parts of z^2 like the burning ship and the real part like
+// the celtic, then subtracts c
+func buffalo(cx, cy float64, maxIter int) float64 {
+	x, y := 0.0, 0.0
+	iteration := 0
+
+	var period periodCheck
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := math.Abs(x*x-y*y) - cx
+		y = 2*math.Abs(x*y) - cy
+		x = xTemp
+		iteration++
+		if period.cycled(x, y) {
+			iteration = maxIter
+			break
+		}
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// magnet orbits escape past this radius, far larger than 2 since they can
+// come back from well outside it
+const magnetEscapeRadius = 100
+
+// magnet1 iterates the magnet type I map z -> ((z^2 + c - 1) / (2z + c - 2))^2
+// from z = 0. Orbits either escape or settle on the fixed point z = 1, both
+// are coloured by how long that took.
+func magnet1(cx, cy float64, maxIter int) float64 {
+	z := complex(0, 0)
+	c := complex(cx, cy)
+
+	for iteration := 0; iteration < maxIter; iteration++ {
+		d := 2*z + c - 2
+		if d == 0 {
+			break
+		}
+		q := (z*z + c - 1) / d
+		z = q * q
+
+		if cmplx.Abs(z-1) < 1e-6 || cmplx.Abs(z) > magnetEscapeRadius {
+			return float64(iteration) + 1
+		}
+	}
+	return interiorValue(real(z), imag(z), maxIter)
+}
+
+// spider iterates z -> z^2 + c with c itself moving each step, c -> c/2 + z.
+// Both start at the pixel, and with c changing there are no cycles to detect.
+func spider(cx, cy float64, maxIter int) float64 {
+	x, y := cx, cy
+	iteration := 0
+
+	for x*x+y*y <= 4 && iteration < maxIter {
+		xTemp := x*x - y*y + cx
+		y = 2*x*y + cy
+		x = xTemp
+		cx, cy = cx/2+x, cy/2+y
+		iteration++
+	}
+
+	if iteration < maxIter {
+		logZn := math.Log(x*x+y*y) / 2
+		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+// roots of z^3 - 1
+var newtonRoots = []complex128{
+	complex(1, 0),
+	complex(-0.5, math.Sqrt(3)/2),
+	complex(-0.5, -math.Sqrt(3)/2),
+}
+
+// newton runs Newton's method for z^3 - 1 starting at z = cx + cy*i and reports
+// which root it converged to (-1 if none) along with the iterations it took
+func newton(cx, cy float64, maxIter int) (rootIndex int, iters float64) {
+	z := complex(cx, cy)
+
+	for iteration := 0; iteration < maxIter; iteration++ {
+		for i, root := range newtonRoots {
+			if cmplx.Abs(z-root) < 1e-6 {
+				return i, float64(iteration)
+			}
+		}
+
+		dz := 3 * z * z
+		if dz == 0 {
+			break
+		}
+		z -= (z*z*z - 1) / dz
+	}
+	return -1, float64(maxIter)
+}
+
+// halley runs Halley's method for f(z) = z^3 - 1, z -> z - 2f*f' / (2f'^2 - f*f2)
+// with f2 the second derivative, which converges a step faster than Newton's.
+// It reports the same as newton.
+func halley(cx, cy float64, maxIter int) (rootIndex int, iters float64) {
+	z := complex(cx, cy)
+
+	for iteration := 0; iteration < maxIter; iteration++ {
+		for i, root := range newtonRoots {
+			if cmplx.Abs(z-root) < 1e-6 {
+				return i, float64(iteration)
+			}
+		}
+
+		f := z*z*z - 1
+		df := 3 * z * z
+		d := 2*df*df - f*6*z
+		if d == 0 {
+			break
+		}
+		z -= 2 * f * df / d
+	}
+	return -1, float64(maxIter)
+}
+
+// findsRoots reports whether a fractal type's values are packed roots
+func findsRoots(fractalType int) bool {
+	return fractalType == FractalNewton || fractalType == FractalHalley
+}
+
+// novaRelaxation scales the Newton step of the nova iteration
+const novaRelaxation = 1.0
+
+// nova runs a relaxed Newton's method for z^3 - 1 with c added after every
+// step, z -> z - R*(z^3-1)/(3z^2) + c, starting at z = 1. Points are coloured
+// by how long the steps take to shrink below the convergence threshold.
+func nova(cx, cy, relaxation float64, maxIter int) float64 {
+	z := complex(1, 0)
+	c := complex(cx, cy)
+
+	for iteration := 0; iteration < maxIter; iteration++ {
+		dz := 3 * z * z
+		if dz == 0 {
+			break
+		}
+		step := complex(relaxation, 0) * (z*z*z - 1) / dz
+		z = z - step + c
+		if cmplx.Abs(step-c) < 1e-6 {
+			return float64(iteration) + 1
+		}
+	}
+	return interiorValue(real(z), imag(z), maxIter)
+}
+
+// rootStride separates the root index from the iteration count in a packed root value
+const rootStride = 1 << 20
+
+// packRoot stores a root-finding result in a single frame value, -1 when no root was found
+func packRoot(rootIndex int, iters float64) float64 {
+	if rootIndex < 0 {
+		return -1
+	}
+	return float64(rootIndex)*rootStride + iters
+}
+
+func unpackRoot(v float64) (rootIndex int, iters float64) {
+	if v < 0 {
+		return -1, 0
+	}
+	root := math.Floor(v / rootStride)
+	return int(root), v - root*rootStride
+}
+
+// colour mapping from: https://stackoverflow.com/questions/16500656/which-color-gradient-is-used-to-color-mandelbrot-in-wikipedia
+var colorMapping = []color.RGBA{
+	{66, 30, 15, 255},
+	{25, 7, 26, 255},
+	{9, 1, 47, 255},
+	{4, 4, 73, 255},
+	{0, 7, 100, 255},
+	{12, 44, 138, 255},
+	{24, 82, 177, 255},
+	{57, 125, 209, 255},
+	{134, 181, 229, 255},
+	{211, 236, 248, 255},
+	{241, 233, 191, 255},
+	{248, 201, 95, 255},
+	{255, 170, 0, 255},
+	{204, 128, 0, 255},
+	{153, 87, 0, 255},
+	{106, 52, 3, 255},
+}
+
+type Game struct {
+	minX, maxX, minY, maxY float64
+	centerX, centerY       float64
+	centerLoX, centerLoY   float64 // lo parts of the double-double center, see preciseCenter
+	juliaX, juliaY         float64
+	zoom                   float64
+	zoomSpeed              float64
+	fractalType            int
+	transFunc              int     // function of the transcendental julia sets
+	power                  int     // exponent used by the Mandelbrot (multibrot) iteration
+	boxScale               float64 // folding scale of the mandelbox
+	escapeRadius           float64 // bailout radius of the Mandelbrot and Julia iterations
+	maxIter                int
+	autoIter               bool // scale maxIter with zoom depth instead of using the slider
+	paletteIndex           int
+	colorMode              int
+	aaSamples              int  // supersampling, each pixel averages aaSamples*aaSamples samples
+	pixelStep              int  // fast preview, only every pixelStep-th pixel is computed
+	quality                int  // the last quality preset picked with Q
+	gpu                    bool // draw shallow Mandelbrot and Julia views with the shader
+	trapShape              TrapShape
+	interiorColoring       bool    // shade points inside the set by their final |z| instead of black
+	interiorSettle         bool    // shade julia interiors by how long their orbits took to settle instead
+	colorCycle             bool    // rotate the palette over time
+	colorCycleSpeed        float64 // palette entries per second
+	paletteOffset          float64
+	colorDensity           float64 // palette entries per iteration
+	gamma                  float64 // brightness curve applied to the finished colours
+	heatmap                bool    // debug colouring by iteration count, overriding the palette
+	dither                 bool    // ordered dithering of palette blends against banding
+	edges                  bool    // line art, only the boundaries in the iteration counts are lit
+	showPeriod             bool    // show the period of the bulb under the view center
+	biomorph               bool    // Pickover biomorphs for the Mandelbrot and Julia
+	colorSpace             int     // space palette entries are blended in
+	animateJulia           bool    // move the julia constant around juliaPathRadius
+	juliaAngle             float64 // position of the julia constant on its path
+	bookmarks              []Bookmark
+	bookmarkIndex          int           // last bookmark saved or visited, -1 for none
+	minibrotIndex          int           // last minibrot snapped to, -1 for none
+	gif                    *gifRecording // zoom animation being recorded, nil when idle
+	export                 *exportJob    // latest image export, nil when idle
+	exportW, exportH       int           // size renderToImage draws the view at, 0 for the window
+	rowsDone               *atomic.Int64 // counts rows as they are computed, for background renders
+	background             bool          // renders on the background worker pool, for exports and gifs
+	copiedAt               time.Time     // when the view was last copied to the clipboard
+	paused                 bool          // auto zoom and animations stopped
+	showHelp               bool          // key bindings overlay
+	lastUpdate             time.Time
+	width, height          int // window size from Layout
+
+	// drag-to-pan state
+	dragging                 bool
+	dragStartX, dragStartY   int
+	dragCenterX, dragCenterY dd
+	dive                     *zoomDive // animated zoom into a clicked point, nil when idle
+	tour                     *guidedTour
+	tourSeconds              float64
+
+	// crossfade between fractal types
+	crossfade bool
+	shown     *ebiten.Image // copy of the view as last drawn
+	fade      *fractalFade  // nil when idle
+
+	// progressive rendering, coarse while the view is moving
+	lastView      viewState
+	viewChangedAt time.Time
+
+	// navigation history for undo and redo
+	history        []viewState
+	historyIndex   int
+	historyPending bool // the view changed and has not settled yet
+
+	// fractal types shown so far, each starts at its default view on its first visit
+	visited [fractalCount]bool
+
+	// cached render of the fractal, redrawn only when its keys change
+	frame      *ebiten.Image
+	frameKey   frameKey
+	frameLook  frameLook
+	frameValid bool
+	renderTime time.Duration // how long the last recompute of the frame took
+	pixels     []byte        // RGBA frame buffer, reused between frames
+	values     []float64     // per-pixel fractal values behind pixels
+	tiles      tileCache     // full resolution values by tile, for panning
+	minimap    *ebiten.Image // thumbnail of the whole set, rendered once
+
+	// the frame is computed in bands of rows, picking up each Draw where the
+	// last one ran out of time
+	banded     bool                   // show every band as it lands, one per Draw
+	bandY      int                    // next row of values to compute
+	bandSample func(x, y int) float64 // sampler of the frame being computed, nil when done
+	bandTiled  bool                   // the frame being computed is put together from tiles
+
+	// temporal accumulation, jittered passes averaged while the view is still
+	accumulate bool
+	accum      accumulator
+
+	// julia picker, the view on the left half and the julia set of the point
+	// under the cursor on the right
+	juliaPicker  bool
+	pickerFrame  *ebiten.Image
+	pickerPixels []byte
+	pickerView   viewState
+	pickerLook   frameLook
+	pickerValid  bool
+}
+
+// frameKey is everything the values of the cached frame depend on
+type frameKey struct {
+	view      viewState
+	step      int
+	aaSamples int
+	valueKind int
+	trapShape TrapShape
+	settle    bool
+}
+
+// frameLook is everything the colours of the cached frame depend on, on top of its values
+type frameLook struct {
+	paletteIndex     int
+	paletteOffset    float64
+	colorDensity     float64
+	colorMode        int
+	interiorColoring bool
+	gamma            float64
+	heatmap          bool
+	dither           bool
+	colorSpace       int
+	edges            bool
+}
+
+func (g *Game) look() frameLook {
+	return frameLook{
+		paletteIndex:     g.paletteIndex,
+		paletteOffset:    g.paletteOffset,
+		colorDensity:     g.colorDensity,
+		colorMode:        g.colorMode,
+		interiorColoring: g.interiorColoring,
+		gamma:            g.gamma,
+		heatmap:          g.heatmap,
+		dither:           g.dither,
+		colorSpace:       g.colorSpace,
+		edges:            g.edges,
+	}
+}
+
+// viewState is everything that decides what the fractal looks like on screen
+type viewState struct {
+	centerX, centerY     float64
+	centerLoX, centerLoY float64
+	zoom                 float64
+	juliaX, juliaY       float64
+	fractalType          int
+	transFunc            int
+	power                int
+	boxScale             float64
+	escapeRadius         float64
+	maxIter              int
+}
+
+func (g *Game) view() viewState {
+	v := viewState{
+		centerX:      g.centerX,
+		centerY:      g.centerY,
+		centerLoX:    g.centerLoX,
+		centerLoY:    g.centerLoY,
+		zoom:         g.zoom,
+		fractalType:  g.fractalType,
+		transFunc:    g.transFunc,
+		power:        g.power,
+		boxScale:     g.boxScale,
+		escapeRadius: g.escapeRadius,
+		maxIter:      g.currentMaxIter(),
+	}
+	// the julia constant only matters to the sets built on it, so picking
+	// one does not redraw the mandelbrot
+	if usesJuliaConstant(g.fractalType) {
+		v.juliaX, v.juliaY = g.juliaX, g.juliaY
+	}
+	return v
+}
+
+// usesJuliaConstant reports whether a fractal type is built on the julia constant
+func usesJuliaConstant(fractalType int) bool {
+	return fractalType == FractalJulia || fractalType == FractalTranscendental || fractalType == FractalGlynn
+}
+
+// movedFrom reports whether v visibly differs from prev: a different fractal,
+// or the view shifting or scaling by more than about a pixel. The slow default
+// zoom speed stays under that, so it still renders at full resolution.
+func (v viewState) movedFrom(prev viewState, pixelSize float64, w int) bool {
+	if v.fractalType != prev.fractalType || v.transFunc != prev.transFunc || v.power != prev.power || v.maxIter != prev.maxIter || v.escapeRadius != prev.escapeRadius || v.boxScale != prev.boxScale {
+		return true
+	}
+	if v.juliaX != prev.juliaX || v.juliaY != prev.juliaY {
+		return true
+	}
+	dx := (v.centerX - prev.centerX) + (v.centerLoX - prev.centerLoX)
+	dy := (v.centerY - prev.centerY) + (v.centerLoY - prev.centerLoY)
+	return math.Abs(dx) > pixelSize ||
+		math.Abs(dy) > pixelSize ||
+		math.Abs(v.zoom/prev.zoom-1) > 1/float64(w)
+}
+
+// colour density limits, from a palette entry every 64 iterations to 8 every iteration
+const (
+	minColorDensity = 1.0 / 64
+	maxColorDensity = 8
+)
+
+// getColor blends between neighbouring palette entries using the fractional
+// part of the smooth iteration count, so the palette has no visible bands.
+// density is palette entries per iteration and offset rotates the palette for
+// colour cycling.
+func getColor(iterations float64, maxIter int, palette []color.RGBA, density, offset float64, style blendStyle) color.RGBA {
+	if iterations < float64(maxIter) && iterations > 0 {
+		return samplePalette(palette, iterations*density+offset, style)
+	}
+	return color.RGBA{A: 255}
+}
+
+// samplePalette returns the colour at a fractional position along the palette, wrapping around its end
+func samplePalette(palette []color.RGBA, pos float64, style blendStyle) color.RGBA {
+	whole, frac := math.Modf(pos)
+	i := int(whole) % len(palette)
+	next := (i + 1) % len(palette)
+	if style.oklab {
+		return lerpOklab(palette[i], palette[next], frac, style.dither)
+	}
+	return lerpColor(palette[i], palette[next], frac, style.dither)
+}
+
+// blendStyle is how samplePalette blends neighbouring palette entries
+type blendStyle struct {
+	dither float64 // as for lerpColor
+	oklab  bool    // interpolate in Oklab rather than RGB
+}
+
+// lerpColor blends from a to b. dither, from 0 up to 1, is added to each
+// channel before it is rounded down to a whole value, 0 just truncates.
+func lerpColor(a, b color.RGBA, t, dither float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(a.R) + (float64(b.R)-float64(a.R))*t + dither),
+		G: uint8(float64(a.G) + (float64(b.G)-float64(a.G))*t + dither),
+		B: uint8(float64(a.B) + (float64(b.B)-float64(a.B))*t + dither),
+		A: uint8(float64(a.A) + (float64(b.A)-float64(a.A))*t + dither),
+	}
+}
+
+var rootColors = []color.RGBA{
+	{230, 60, 60, 255},
+	{60, 200, 90, 255},
+	{60, 110, 230, 255},
+}
+
+// getRootColor shades a root's colour by how many iterations it took to converge
+func getRootColor(rootIndex int, iters float64) color.RGBA {
+	if rootIndex < 0 || rootIndex >= len(rootColors) {
+		return color.RGBA{A: 255}
+	}
+
+	clr := rootColors[rootIndex]
+	shade := math.Pow(0.95, iters)
+	return color.RGBA{
+		R: uint8(float64(clr.R) * shade),
+		G: uint8(float64(clr.G) * shade),
+		B: uint8(float64(clr.B) * shade),
+		A: 255,
+	}
+}
+
+func (g *Game) Update() error {
+	now := time.Now()
+	elapsed := now.Sub(g.lastUpdate).Seconds()
+	g.lastUpdate = now
+
+	// sidebar interaction
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && !g.dragging {
+		x, y := ebiten.CursorPosition()
+		if x < sidebarWidth {
+			if y >= sliderTop && y <= sliderTop+sliderHeight {
+				t := float64(y-sliderTop) / sliderHeight
+				if x < zoomSliderX+sliderGrab {
+					g.zoomSpeed = sliderZoomSpeed(1 - 2*t)
+				} else if x < iterSliderX+sliderGrab {
+					g.maxIter = sliderMinIter + int(t*(sliderMaxIter-sliderMinIter))
+				}
+			}
+		}
+	}
+
+	// sidebar buttons only fire once per click
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		if x < sidebarWidth {
+			if y >= 300 && y <= 340 {
+				g.toggleFractal()
+			} else if y >= 350 && y <= 390 {
+				g.autoIter = !g.autoIter
+			} else if y >= 400 && y <= 440 {
+				g.resetView()
+			}
+		}
+	}
+
+	// drag to pan, only when the press started outside the sidebar.
+	// Clicking the minimap jumps there instead.
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		if g.inMinimap(x, y) {
+			g.setCenter(g.minimapToComplex(x, y))
+		} else if x >= sidebarWidth {
+			g.dive, g.tour = nil, nil
+			g.dragging = true
+			g.dragStartX, g.dragStartY = x, y
+			g.dragCenterX, g.dragCenterY = g.preciseCenter()
+		}
+	}
+	if g.dragging {
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			x, y := ebiten.CursorPosition()
+			width, height := g.viewExtent()
+			w, h := g.viewSize()
+			g.setPreciseCenter(g.dragCenterX, g.dragCenterY)
+			g.moveCenter(-float64(x-g.dragStartX)*width/float64(w), -float64(y-g.dragStartY)*height/float64(h))
+		} else {
+			g.dragging = false
+			// a press that barely moved is a click, which dives into the clicked point
+			x, y := ebiten.CursorPosition()
+			if abs(x-g.dragStartX) <= clickSlop && abs(y-g.dragStartY) <= clickSlop {
+				g.setPreciseCenter(g.dragCenterX, g.dragCenterY)
+				g.startDive(g.cursorOffset(g.dragStartX, g.dragStartY))
+			}
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		g.toggleTour()
+	}
+	if !g.paused {
+		g.updateDive(elapsed)
+		g.updateTour(elapsed)
+	}
+	g.updateFade(elapsed)
+
+	// shift nudges the julia constant instead of moving the view, by juliaStep
+	// per notch of the wheel, and a held arrow counts as a notch every 60th of a second
+	nudge := ebiten.IsKeyPressed(ebiten.KeyShift) && usesJuliaConstant(g.fractalType)
+	if nudge {
+		dx, dy := ebiten.Wheel()
+		held := elapsed * 60
+		if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+			dx -= held
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+			dx += held
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+			dy -= held
+		}
+		if ebiten.IsKeyPressed(ebiten.KeyArrowDown) {
+			dy += held
+		}
+		if dx != 0 || dy != 0 {
+			g.animateJulia = false
+			g.juliaX += dx * juliaStep
+			g.juliaY += dy * juliaStep
+		}
+	}
+
+	// scroll to zoom, keeping the point under the cursor fixed
+	if _, wheelY := ebiten.Wheel(); wheelY != 0 && !nudge {
+		x, y := ebiten.CursorPosition()
+		if x >= sidebarWidth {
+			dx, dy := g.cursorOffset(x, y)
+			oldZoom := g.zoom
+			g.zoom = math.Max(1, math.Min(g.zoom*math.Pow(1.1, wheelY), maxZoom))
+			ratio := oldZoom / g.zoom
+			g.moveCenter(dx*(1-ratio), dy*(1-ratio))
+		}
+	}
+
+	// keyboard navigation, moving a constant fraction of the visible region per second
+	width, height := g.viewExtent()
+	panStep := 0.5 * elapsed
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) && !nudge {
+		g.moveCenter(-width*panStep, 0)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) && !nudge {
+		g.moveCenter(width*panStep, 0)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) && !nudge {
+		g.moveCenter(0, -height*panStep)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) && !nudge {
+		g.moveCenter(0, height*panStep)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyEqual) || ebiten.IsKeyPressed(ebiten.KeyNumpadAdd) {
+		g.zoom *= math.Pow(2, elapsed)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyMinus) || ebiten.IsKeyPressed(ebiten.KeyNumpadSubtract) {
+		g.zoom /= math.Pow(2, elapsed)
+	}
+
+	// middle click recenters on the cursor, keeping the zoom
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) {
+		x, y := ebiten.CursorPosition()
+		if x >= sidebarWidth {
+			g.dive, g.tour = nil, nil
+			g.moveCenter(g.cursorOffset(x, y))
+		}
+	}
+
+	// pick a julia constant from the mandelbrot
+	if g.fractalType == FractalMandelbrot && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		x, y := ebiten.CursorPosition()
+		if x >= sidebarWidth {
+			g.juliaX, g.juliaY = g.cursorToComplex(x, y)
+			g.switchFractal(FractalJulia)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.saveScreenshot()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		g.saveHighRes()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		g.recordGIF()
+	}
+	if g.gif != nil && g.gif.done.Load() {
+		g.gif = nil
+	}
+	if g.export != nil && g.export.done.Load() {
+		g.export = nil
+	}
+	// Q steps through the quality presets
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		g.nextQuality()
+	}
+
+	// R steps the fast preview through 1, 2, 4 and 8 pixel blocks
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.pixelStep *= 2
+		if g.pixelStep > 8 {
+			g.pixelStep = 1
+		}
+	}
+
+	// Tab switches between whole frames and filling the frame in bands
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.banded = !g.banded
+	}
+
+	// L switches the colour space palette blends are made in
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		g.colorSpace = (g.colorSpace + 1) % colorSpaceCount
+	}
+
+	// W toggles ordered dithering
+	if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		g.dither = !g.dither
+	}
+
+	// F3 shows how many iterations each point took
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.heatmap = !g.heatmap
+	}
+
+	// F2 shows the period of the bulb at the view center
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		g.showPeriod = !g.showPeriod
+	}
+
+	// F4 switches to line art of the set's boundaries
+	if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+		g.edges = !g.edges
+	}
+
+	// F6 grows biomorphs on the Mandelbrot and Julia
+	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+		g.biomorph = !g.biomorph
+	}
+
+	// the whole scene, F5 saves it and F9 loads it back
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		if err := g.SaveState(statePath); err != nil {
+			log.Printf("saving scene: %v", err)
+		} else {
+			log.Printf("saved %s", statePath)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		if err := g.LoadState(statePath); err != nil {
+			log.Printf("loading scene: %v", err)
+		}
+	}
+	// C copies the view to the clipboard
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.copyView()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		g.addBookmark()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		g.nextBookmark()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.paletteIndex = (g.paletteIndex + 1) % len(palettes)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		g.colorMode = (g.colorMode + 1) % colorModeCount
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		g.trapShape = (g.trapShape + 1) % trapShapeCount
+	}
+	// I shades the interior by the final |z|, Shift+I julia interiors by how
+	// quickly their orbits settle
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.interiorSettle = !g.interiorSettle
+		} else {
+			g.interiorColoring = !g.interiorColoring
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		switch g.aaSamples {
+		case 1:
+			g.aaSamples = 2
+		case 2:
+			g.aaSamples = 4
+		default:
+			g.aaSamples = 1
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		g.gpu = !g.gpu
+	}
+
+	// colour cycling, K toggles it and shift+K changes its speed
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.colorCycleSpeed *= 2
+			if g.colorCycleSpeed > 16 {
+				g.colorCycleSpeed = 1
+			}
+		} else {
+			g.colorCycle = !g.colorCycle
+		}
+	}
+	if g.colorCycle && !g.paused {
+		g.paletteOffset = math.Mod(g.paletteOffset+g.colorCycleSpeed*elapsed, float64(len(g.palette())))
+	}
+
+	// animated julia constant, J starts and pauses it
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		g.animateJulia = !g.animateJulia
+		if g.animateJulia {
+			g.switchFractal(FractalJulia)
+		}
+	}
+	if g.animateJulia && g.fractalType == FractalJulia && !g.paused {
+		g.juliaAngle = math.Mod(g.juliaAngle+juliaPathSpeed*elapsed, 2*math.Pi)
+		g.juliaX = juliaPathRadius * math.Cos(g.juliaAngle)
+		g.juliaY = juliaPathRadius * math.Sin(g.juliaAngle)
+	}
+
+	// escape radius, doubling between 2 and 1024
+	if inpututil.IsKeyJustPressed(ebiten.KeySemicolon) && g.escapeRadius > 2 {
+		g.escapeRadius /= 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyApostrophe) && g.escapeRadius < 1024 {
+		g.escapeRadius *= 2
+	}
+
+	// gamma, in steps of 0.1 between 0.2 and 5, and with shift (< and >) the
+	// colour density, halving or doubling
+	if inpututil.IsKeyJustPressed(ebiten.KeyComma) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.colorDensity = math.Max(g.colorDensity/2, minColorDensity)
+		} else if g.gamma > 0.25 {
+			g.gamma = math.Round(g.gamma*10-1) / 10
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.colorDensity = math.Min(g.colorDensity*2, maxColorDensity)
+		} else if g.gamma < 4.95 {
+			g.gamma = math.Round(g.gamma*10+1) / 10
+		}
+	}
+
+	// T cycles through the fractals, shift+T backwards
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.cycleFractal(-1)
+		} else {
+			g.cycleFractal(1)
+		}
+	}
+
+	// V goes back to the default view of the current fractal
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		g.defaultView()
+	}
+
+	// F snaps to the minibrots along the antenna
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		g.nextMinibrot()
+	}
+
+	// julia picker, D splits the window into the mandelbrot and the julia
+	// set of the point under the cursor
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		g.juliaPicker = !g.juliaPicker
+		if g.juliaPicker {
+			g.switchFractal(FractalMandelbrot)
+			g.animateJulia = false
+		}
+	}
+	if g.juliaPicker {
+		g.pickJulia()
+	}
+
+	// function of the transcendental julia sets
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) && g.fractalType == FractalTranscendental {
+		g.nextTransFunc()
+	}
 
-	for x*x+y*y <= 4 && iteration < maxIter {
-		xTemp := x*x - y*y + cx
-		y = 2*x*y + cy
-		x = xTemp
-		iteration++
+	// undo and redo, ctrl+shift+Z redoes too
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+			if ebiten.IsKeyPressed(ebiten.KeyShift) {
+				g.redo()
+			} else {
+				g.undo()
+			}
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+			g.redo()
+		}
 	}
 
-	if iteration < maxIter {
-		logZn := math.Log(x*x+y*y) / 2
-		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+	// multibrot power, or the scale of the mandelbox
+	if g.fractalType == FractalMandelbox {
+		if inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) {
+			g.boxScale = stepBoxScale(g.boxScale, -boxScaleStep)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) {
+			g.boxScale = stepBoxScale(g.boxScale, boxScaleStep)
+		}
+	} else {
+		if inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft) && g.power > 2 {
+			g.power--
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyBracketRight) && g.power < 8 {
+			g.power++
+		}
 	}
-	return float64(maxIter)
+
+	// F10 averages jittered passes of a still view
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) {
+		g.accumulate = !g.accumulate
+		g.frameValid = false
+	}
+
+	// F7 steps the update rate through tpsSteps, F8 toggles vsync
+	if inpututil.IsKeyJustPressed(ebiten.KeyF7) {
+		ebiten.SetTPS(nextTPS(ebiten.TPS()))
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
+		ebiten.SetVsyncEnabled(!ebiten.IsVsyncEnabled())
+	}
+
+	// H lists the bindings
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		g.showHelp = !g.showHelp
+	}
+
+	// space pauses the zoom and animations, anything left to render is the
+	// cached frame
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.paused = !g.paused
+	}
+
+	// a negative speed zooms out until the zoom bottoms out at 1
+	g.zoomSpeed = math.Max(-maxZoomSpeed, math.Min(g.zoomSpeed, maxZoomSpeed))
+	if !g.paused && g.tour == nil {
+		g.zoom *= math.Pow(1+g.zoomSpeed, elapsed)
+	}
+	g.zoom = math.Max(1, math.Min(g.zoom, maxZoom))
+
+	view := g.view()
+	width, _ = g.viewExtent()
+	viewW, _ := g.viewSize()
+	pixelSize := width / float64(viewW)
+	if view.movedFrom(g.lastView, pixelSize, viewW) {
+		g.viewChangedAt = now
+		g.historyPending = true
+	}
+	g.lastView = view
+	if (g.historyPending || len(g.history) == 0) && now.Sub(g.viewChangedAt) >= settleTime {
+		g.recordHistory(view, pixelSize, viewW)
+		g.historyPending = false
+	}
+
+	return nil
 }
 
-func julia(x, y, cx, cy float64, maxIter int) float64 {
-	iteration := 0
+func (g *Game) toggleFractal() {
+	g.cycleFractal(1)
+}
 
-	for x*x+y*y <= 4 && iteration < maxIter {
-		xTemp := x*x - y*y + cx
-		y = 2*x*y + cy
-		x = xTemp
-		iteration++
+// cycleFractal moves step places through the fractal types, wrapping around
+func (g *Game) cycleFractal(step int) {
+	next := ((g.fractalType+step)%fractalCount + fractalCount) % fractalCount
+	if next == FractalJulia {
+		g.juliaX = g.centerX
+		g.juliaY = g.centerY
 	}
+	if next == FractalTranscendental {
+		g.juliaX, g.juliaY = transSeed(g.transFunc)
+	}
+	if next == FractalGlynn {
+		g.juliaX, g.juliaY = glynnX, glynnY
+	}
+	g.switchFractal(next)
+}
 
-	if iteration < maxIter {
-		logZn := math.Log(x*x+y*y) / 2
-		return float64(iteration) + 1 - math.Log(logZn)/math.Log(2)
+// switchFractal changes the fractal type, starting at its default view the
+// first time it is shown
+func (g *Game) switchFractal(fractalType int) {
+	if fractalType != g.fractalType {
+		g.startFade()
+	}
+	g.fractalType = fractalType
+	if !g.visited[fractalType] {
+		g.visited[fractalType] = true
+		g.defaultView()
 	}
-	return float64(maxIter)
 }
 
-// colour mapping from: https://stackoverflow.com/questions/16500656/which-color-gradient-is-used-to-color-mandelbrot-in-wikipedia
-var colorMapping = []color.RGBA{
-	{66, 30, 15, 255},
-	{25, 7, 26, 255},
-	{9, 1, 47, 255},
-	{4, 4, 73, 255},
-	{0, 7, 100, 255},
-	{12, 44, 138, 255},
-	{24, 82, 177, 255},
-	{57, 125, 209, 255},
-	{134, 181, 229, 255},
-	{211, 236, 248, 255},
-	{241, 233, 191, 255},
-	{248, 201, 95, 255},
-	{255, 170, 0, 255},
-	{204, 128, 0, 255},
-	{153, 87, 0, 255},
-	{106, 52, 3, 255},
+// defaultView frames the current fractal at its default view
+func (g *Game) defaultView() {
+	x, y, zoom := fractalDefaultView(g.fractalType)
+	g.setCenter(x, y)
+	g.zoom = zoom
 }
 
-type Game struct {
-	minX, maxX, minY, maxY float64
-	centerX, centerY       float64
-	juliaX, juliaY         float64
-	zoom                   float64
-	zoomSpeed              float64
-	fractalType            int
-	lastUpdate             time.Time
+// fractalDefaultView is where each fractal starts: Seahorse Valley for the
+// Mandelbrot, and the whole set for the others
+func fractalDefaultView(fractalType int) (centerX, centerY, zoom float64) {
+	switch fractalType {
+	case FractalMandelbrot:
+		return defaultCenterX, defaultCenterY, defaultZoom
+	case FractalBurningShip:
+		return -0.4, -0.5, 1
+	case FractalTricorn:
+		return -0.3, 0, 1
+	case FractalCeltic:
+		return -0.6, 0, 1
+	case FractalNova:
+		return -0.4, 0, 1
+	case FractalBuffalo:
+		return 0.4, 0.5, 1
+	case FractalMagnet1:
+		return 1.5, 0, 1
+	case FractalLyapunov:
+		return 3.2, 3.4, 1.75
+	case FractalSpider:
+		return -0.6, 0, 1
+	case FractalGlynn:
+		return 0.3, 0, 1.6
+	case FractalPerpMandelbrot:
+		return -0.66, 0, 1
+	case FractalPerpBurningShip:
+		return -0.5, 0, 1
+	case FractalManowar:
+		return -0.5, 0, 2.5
+	}
+	// julia sets, the newton, halley, collatz and mandelbox fractals are centered on the origin
+	return 0, 0, 1
+}
+
+// resetView returns to the default Mandelbrot view
+func (g *Game) resetView() {
+	g.switchFractal(FractalMandelbrot)
+	g.power = 2
+	g.setCenter(defaultCenterX, defaultCenterY)
+	g.zoom = defaultZoom
+	g.zoomSpeed = defaultZoomSpeed
+}
+
+// viewBounds returns the region of the complex plane currently on screen.
+// minX..maxY span the initial window at zoom 1, a bigger window sees more of the plane.
+func (g *Game) viewBounds() (minX, maxX, minY, maxY float64) {
+	width, height := g.viewExtent()
+	return g.centerX - width/2, g.centerX + width/2, g.centerY - height/2, g.centerY + height/2
 }
 
-func getColor(iterations, maxIter int) color.RGBA {
-	if iterations < maxIter && iterations > 0 {
-		i := iterations % len(colorMapping)
-		return colorMapping[i]
+// viewExtent is the width and height of the region on screen. Pixels are
+// square, sized so the initial window fits in the default window at zoom 1,
+// and the other direction follows the screen's aspect ratio.
+func (g *Game) viewExtent() (width, height float64) {
+	w, h := g.viewSize()
+	pixelSize := math.Max((g.maxX-g.minX)/(screenWidth-sidebarWidth), (g.maxY-g.minY)/screenHeight) / g.zoom
+	width = pixelSize * float64(w)
+	height = pixelSize * float64(h)
+	if g.exportW > 0 {
+		// an export takes in everything the window shows, its own aspect
+		// ratio widening the view one way so pixels stay square
+		pixelSize = math.Max(width/float64(g.exportW), height/float64(g.exportH))
+		width, height = pixelSize*float64(g.exportW), pixelSize*float64(g.exportH)
+	}
+	if g.juliaPicker {
+		// each half of the julia picker sees as much as the whole window would
+		width, height = width*2, height*2
 	}
-	return color.RGBA{}
+	return width, height
 }
 
-func (g *Game) Update() error {
-	now := time.Now()
-	elapsed := now.Sub(g.lastUpdate).Seconds()
-	g.lastUpdate = now
+// cursorOffset is how far the point under the cursor is from the view center.
+// Unlike cursorToComplex it stays accurate however deep the zoom.
+func (g *Game) cursorOffset(x, y int) (float64, float64) {
+	w, h := g.viewSize()
+	width, height := g.viewExtent()
+	return width * (float64(x-sidebarWidth)/float64(w) - 0.5), height * (float64(y)/float64(h) - 0.5)
+}
 
-	// sidebar interaction
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		x, y := ebiten.CursorPosition()
-		if x < 100 {
-			if y >= 70 && y <= 270 {
-				g.zoomSpeed = (float64(y-70) / 200) * 0.5
-			} else if y >= 300 && y <= 340 {
-				g.toggleFractal()
+// screenToComplex maps a pixel on a w*h screen to its point in the complex plane
+func (g *Game) screenToComplex(x, y, w, h int) (float64, float64) {
+	minX, maxX, minY, maxY := g.viewBounds()
+	cx := minX + (maxX-minX)*float64(x)/float64(w)
+	cy := minY + (maxY-minY)*float64(y)/float64(h)
+	return cx, cy
+}
+
+// sliderZoomSpeed maps a position on the zoom speed slider, from 1 at the top
+// to -1 at the bottom, to a zoom speed. The top half zooms in and the bottom
+// half zooms out, logarithmically so speeds near the middle are slow enough
+// for fine control.
+func sliderZoomSpeed(pos float64) float64 {
+	speed := zoomSpeedFine * (math.Pow(maxZoomSpeed/zoomSpeedFine+1, math.Abs(pos)) - 1)
+	return math.Copysign(speed, pos)
+}
+
+// zoomSpeedSlider is the inverse of sliderZoomSpeed
+func zoomSpeedSlider(speed float64) float64 {
+	pos := math.Log(math.Abs(speed)/zoomSpeedFine+1) / math.Log(maxZoomSpeed/zoomSpeedFine+1)
+	return math.Copysign(pos, speed)
+}
+
+// autoMaxIter grows the iteration count logarithmically with zoom so deep zooms keep their detail
+func autoMaxIter(zoom float64) int {
+	return min(200+int(50*math.Log10(math.Max(zoom, 1))), 5000)
+}
+
+// currentMaxIter is the iteration count the next frame will be rendered with
+func (g *Game) currentMaxIter() int {
+	if g.autoIter {
+		return autoMaxIter(g.zoom)
+	}
+	return g.maxIter
+}
+
+// cursorToComplex maps a cursor position on the window to its point in the complex plane
+func (g *Game) cursorToComplex(x, y int) (float64, float64) {
+	w, h := g.viewSize()
+	return g.screenToComplex(x-sidebarWidth, y, w, h)
+}
+
+// viewSize is the size of the fractal area right of the sidebar, or its left
+// half in the julia picker
+func (g *Game) viewSize() (int, int) {
+	if g.juliaPicker {
+		return (g.width - sidebarWidth) / 2, g.height
+	}
+	return g.width - sidebarWidth, g.height
+}
+
+// pointValue computes the fractal at a single point. Escape-time fractals give
+// their smooth iteration count, root-finding ones a packed root and iteration count.
+func (g *Game) pointValue(cx, cy float64, maxIter int) float64 {
+	switch g.fractalType {
+	case FractalMandelbrot:
+		return multibrot(cx, cy, g.power, g.escapeRadius, maxIter)
+	case FractalJulia:
+		return julia(cx, cy, g.juliaX, g.juliaY, g.escapeRadius, g.showSettle(), maxIter)
+	case FractalBurningShip:
+		return burningShip(cx, cy, maxIter)
+	case FractalTricorn:
+		return tricorn(cx, cy, maxIter)
+	case FractalNewton:
+		return packRoot(newton(cx, cy, maxIter))
+	case FractalPhoenix:
+		return phoenix(cx, cy, phoenixP, maxIter)
+	case FractalCeltic:
+		return celtic(cx, cy, maxIter)
+	case FractalNova:
+		return nova(cx, cy, novaRelaxation, maxIter)
+	case FractalTranscendental:
+		return transcendental(cx, cy, g.juliaX, g.juliaY, g.transFunc, maxIter)
+	case FractalBuffalo:
+		return buffalo(cx, cy, maxIter)
+	case FractalMagnet1:
+		return magnet1(cx, cy, maxIter)
+	case FractalCollatz:
+		return collatz(cx, cy, maxIter)
+	case FractalLyapunov:
+		return lyapunov(cx, cy, lyapunovSequence, maxIter)
+	case FractalSpider:
+		return spider(cx, cy, maxIter)
+	case FractalGlynn:
+		return glynn(cx, cy, g.juliaX, g.juliaY, glynnPower, maxIter)
+	case FractalPerpMandelbrot:
+		return perpMandelbrot(cx, cy, maxIter)
+	case FractalPerpBurningShip:
+		return perpBurningShip(cx, cy, maxIter)
+	case FractalMandelbox:
+		return mandelbox(cx*mandelboxSpan, cy*mandelboxSpan, g.boxScale, maxIter)
+	case FractalManowar:
+		return manowar(cx, cy, maxIter)
+	case FractalHalley:
+		return packRoot(halley(cx, cy, maxIter))
+	}
+	return float64(maxIter)
+}
+
+// pixelSampler returns the function computing the value of pixel (x, y) on a
+// w*h frame, using whichever arithmetic the current zoom needs
+func (g *Game) pixelSampler(w, h, maxIter int) func(x, y int) float64 {
+	if g.useBigFloat() {
+		return g.perturbationSampler(w, h, maxIter)
+	}
+	if g.useDD() {
+		return g.ddSampler(w, h, maxIter)
+	}
+
+	sample := g.pointSampler(maxIter)
+	return func(x, y int) float64 {
+		return sample(g.screenToComplex(x, y, w, h))
+	}
+}
+
+// pointSampler returns the function computing the float64 value of a point,
+// whatever the frame values hold
+func (g *Game) pointSampler(maxIter int) func(cx, cy float64) float64 {
+	switch g.valueKind() {
+	case valuesDistance:
+		return func(cx, cy float64) float64 {
+			return mandelbrotDE(cx, cy, maxIter)
+		}
+	case valuesTrap:
+		trap := g.trapShape
+		return func(cx, cy float64) float64 {
+			return mandelbrotOrbitTrap(cx, cy, maxIter, trap)
+		}
+	case valuesBiomorph:
+		if g.fractalType == FractalJulia {
+			juliaX, juliaY := g.juliaX, g.juliaY
+			return func(cx, cy float64) float64 {
+				return biomorphValue(biomorph(cx, cy, juliaX, juliaY, maxIter))
 			}
 		}
+		return func(cx, cy float64) float64 {
+			return biomorphValue(biomorph(0, 0, cx, cy, maxIter))
+		}
 	}
 
-	g.zoom *= math.Pow(1+g.zoomSpeed, elapsed)
-	g.zoom = math.Max(1, math.Min(g.zoom, 1e15))
+	return func(cx, cy float64) float64 {
+		return g.pointValue(cx, cy, maxIter)
+	}
+}
 
-	return nil
+// parallelRows splits the rows [0, h) into chunks for g's worker pool and waits for fn to finish on each
+func (g *Game) parallelRows(h int, fn func(startY, endY int)) {
+	jobs := g.workerPool()
+	chunks := workerCount * chunksPerWorker
+	rowsPerChunk := max((h+chunks-1)/chunks, 1)
+
+	var wg sync.WaitGroup
+	for startY := 0; startY < h; startY += rowsPerChunk {
+		wg.Add(1)
+		jobs <- rowJob{fn: fn, startY: startY, endY: min(startY+rowsPerChunk, h), wg: &wg}
+	}
+	wg.Wait()
 }
 
-func (g *Game) toggleFractal() {
-	g.fractalType = (g.fractalType + 1) % 2
-	if g.fractalType == FractalJulia {
-		g.juliaX = g.centerX
-		g.juliaY = g.centerY
+// an anti-aliased render is computed about this many values at a time, so a
+// big export never holds its whole supersampled frame
+var stripValues = 1 << 22
+
+// renderFractal fills pixels (RGBA, w*h*4 bytes) with the current view. With
+// anti-aliasing the values are computed and coloured in strips of rows.
+func (g *Game) renderFractal(pixels []byte, w, h int) {
+	n := g.aaSamples
+	maxIter := g.currentMaxIter()
+	valuesWidth, valuesHeight := w*n, h*n
+	rows := h
+	if n > 1 {
+		rows = min(max(stripValues/(valuesWidth*n), 1), h)
+	}
+
+	// the histogram and log scale modes colour by the whole frame, which
+	// a strip doesn't see. One value per pixel is a fair sample of it.
+	var stats frameStats
+	wholeFrame := rows == h
+	if !wholeFrame && (g.colorMode == ColorHistogram || g.colorMode == ColorLog) {
+		preview := *g
+		preview.rowsDone = nil
+		stats = g.frameStats(computeFrame(&preview, w, h), maxIter)
+	}
+
+	// a strip holds its value rows plus one either side for edges. The rows
+	// below it are the first of the next strip, so they are kept rather than
+	// computed again.
+	sample := g.pixelSampler(valuesWidth, valuesHeight, maxIter)
+	buf := make([]float64, (rows*n+2)*valuesWidth)
+	lo, done := 0, 0 // first value row in buf, first not computed yet
+	for startY := 0; startY < h; startY += rows {
+		endY := min(startY+rows, h)
+		from, to := max(startY*n-1, 0), min(endY*n+1, valuesHeight)
+		copy(buf, buf[(from-lo)*valuesWidth:(done-lo)*valuesWidth])
+		lo = from
+
+		strip := buf[:(to-lo)*valuesWidth]
+		g.computeRows(func(x, y int) float64 { return sample(x, lo+y) }, strip, valuesWidth, to-lo, 1, done-lo, to-lo)
+		done = to
+		if wholeFrame {
+			stats = g.frameStats(strip, maxIter)
+		}
+		g.colorRows(pixels, strip, w, n, maxIter, stats, lo, startY, endY)
 	}
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	maxIter := 200
-
-	width := (g.maxX - g.minX) / g.zoom
-	height := (g.maxY - g.minY) / g.zoom
-	minX := g.centerX - width/2
-	maxX := g.centerX + width/2
-	minY := g.centerY - height/2
-	maxY := g.centerY + height/2
-
-	// calc fractal set for each pixel
-	for y := 0; y < screen.Bounds().Dy(); y++ {
-		for x := 0; x < screen.Bounds().Dx(); x++ {
-			cx := minX + (maxX-minX)*float64(x)/float64(screen.Bounds().Dx())
-			cy := minY + (maxY-minY)*float64(y)/float64(screen.Bounds().Dy())
-
-			var iterations float64
-			switch g.fractalType {
-			case FractalMandelbrot:
-				iterations = mandelbrot(cx, cy, maxIter)
-			case FractalJulia:
-				iterations = julia(cx, cy, g.juliaX, g.juliaY, maxIter)
-			}
-			clr := getColor(int(iterations), maxIter)
+// computeFrame returns the values of the current view on a w*h frame at full
+// resolution: smooth iteration counts, or whatever valueKind says. It only does
+// the maths, with no window or image involved, so headless rendering and
+// exports share it.
+func computeFrame(g *Game, w, h int) []float64 {
+	values := make([]float64, w*h)
+	g.computeValues(values, w, h, 1)
+	return values
+}
+
+// computeValues fills values (w*h) with the fractal for the current view.
+// Only every step-th pixel is computed, filling a step*step block.
+func (g *Game) computeValues(values []float64, w, h, step int) {
+	sample := g.pixelSampler(w, h, g.currentMaxIter())
+	g.computeRows(sample, values, w, h, step, 0, h)
+}
 
-			vector.DrawFilledRect(screen, float32(x), float32(y), 1, 1, clr, false)
+// computeRows fills rows startY to endY of values (w*h) from sample. Blocks
+// of step*step take the value of their top left pixel, so startY should be a
+// multiple of step.
+func (g *Game) computeRows(sample func(x, y int) float64, values []float64, w, h, step, startY, endY int) {
+	rowsDone := g.rowsDone
+	g.parallelRows(endY-startY, func(fromY, toY int) {
+		for y := startY + fromY; y < startY+toY; y++ {
+			if rowsDone != nil {
+				rowsDone.Add(1)
+			}
+			// rows inside a block are filled by the row that starts it
+			if y%step != 0 {
+				continue
+			}
+			for x := 0; x < w; x += step {
+				v := sample(x, y)
+				for by := y; by < min(y+step, h); by++ {
+					for bx := x; bx < min(x+step, w); bx++ {
+						values[by*w+bx] = v
+					}
+				}
+			}
 		}
+	})
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	// a zoom below 1 (or zero) would blow the view bounds up to infinity
+	g.zoom = math.Max(g.zoom, 1)
+
+	// only the area right of the sidebar is rendered
+	w, h := g.viewSize()
+	if g.useGPU() {
+		g.drawGPU(screen, w, h)
+	} else {
+		g.updateFrame(w, h)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(sidebarWidth, 0)
+		screen.DrawImage(g.frame, op)
+	}
+	g.drawFade(screen, w, h)
+	if g.juliaPicker {
+		g.drawPicker(screen, w, h)
 	}
 
+	drawMinimap(screen, g)
+	drawCrosshair(screen, g)
 	drawSidebar(screen, g)
-	drawInfo(screen, g.zoomSpeed, g.zoom, g.centerX, g.centerY, g.fractalType)
+	drawInfo(screen, g)
+	if g.showHelp {
+		drawHelp(screen, g)
+	}
+}
+
+// updateFrame brings the cached w*h frame up to date with the view. Only what
+// changed since the cached frame is redone, colours can be redone from the
+// stored values.
+func (g *Game) updateFrame(w, h int) {
+	n := g.aaSamples
+	if g.frame == nil || g.frame.Bounds().Dx() != w || g.frame.Bounds().Dy() != h {
+		g.frame = ebiten.NewImage(w, h)
+		g.pixels = make([]byte, w*h*4)
+		g.frameValid = false
+	}
+	if len(g.values) != w*h*n*n {
+		g.values = make([]float64, w*h*n*n)
+		g.frameValid = false
+	}
+
+	// coarse preview until the view has settled, unless the view only panned
+	// and most of the frame is already in the tile cache
+	tiled := g.canTile()
+	step := g.pixelStep
+	moving := time.Since(g.viewChangedAt) < settleTime
+	if moving && !(tiled && g.tilesValid(w*n, h*n)) {
+		step = max(step, 4)
+	}
+
+	key := frameKey{view: g.view(), step: step, aaSamples: n, valueKind: g.valueKind(), trapShape: g.trapShape, settle: g.showSettle()}
+	look := g.look()
+	recolor := !g.frameValid || look != g.frameLook
+	if !g.frameValid || key != g.frameKey {
+		start := time.Now()
+		// the bands below fill the frame in, over as many frames as they take
+		g.bandY, g.bandSample, g.bandTiled = 0, nil, false
+		if tiled && step == 1 {
+			g.prepareTiles(w*n, h*n)
+			g.bandTiled = true
+		} else {
+			g.bandSample = g.pixelSampler(w*n, h*n, key.view.maxIter)
+		}
+		g.renderTime = time.Since(start)
+		g.frameKey = key
+		recolor = true
+	}
+	// bands until the frame is done or this frame's budget has gone, so input
+	// is still handled during heavy renders. Banded mode shows every band.
+	start := time.Now()
+	for g.bandY < h*n {
+		if g.bandTiled {
+			// a band of a tiled frame is a row of tiles
+			g.bandY = g.computeTileRow(g.values, w*n, h*n, g.bandY)
+		} else {
+			// step strides the supersampled grid, so at step 1 every
+			// sub-pixel gets a sample of its own
+			end := min(g.bandY+bandRows*step*n, h*n)
+			g.computeRows(g.bandSample, g.values, w*n, h*n, step, g.bandY, end)
+			g.bandY = end
+		}
+		recolor = true
+		if (g.banded && !moving) || time.Since(start) >= frameBudget {
+			break
+		}
+	}
+	if g.bandY == h*n {
+		g.bandSample, g.bandTiled = nil, false
+	}
+	g.renderTime += time.Since(start)
+	if recolor {
+		g.colorFrame(g.pixels, g.values, w, h, n, key.view.maxIter)
+		g.frame.WritePixels(g.pixels)
+		g.frameLook = look
+		g.frameValid = true
+	}
+	if g.accumulate && !moving && step == 1 && !g.rendering() {
+		g.accumulateFrame(w, h, recolor)
+	}
+}
+
+func periodInfo(period int) string {
+	if period == 0 {
+		return "Period: None"
+	}
+	return fmt.Sprintf("Period: %d", period)
+}
+
+// update rates F7 steps through
+var tpsSteps = []int{15, 30, 60, 120}
+
+// nextTPS is the step after tps, wrapping around. A rate set by -fps that
+// isn't a step goes to the first step above it.
+func nextTPS(tps int) int {
+	for _, step := range tpsSteps {
+		if step > tps {
+			return step
+		}
+	}
+	return tpsSteps[0]
+}
+
+func fpsInfo() string {
+	vsync := "Off"
+	if ebiten.IsVsyncEnabled() {
+		vsync = "On"
+	}
+	return fmt.Sprintf("FPS: %.0f (target %d, vsync %s)", ebiten.ActualFPS(), ebiten.TPS(), vsync)
+}
+
+// frames are computed in bands of this many rows of samples, and the frame
+// budget is checked between bands
+const (
+	bandRows    = 32
+	frameBudget = 100 * time.Millisecond
+)
+
+// rendering reports whether the bands of a frame are still being computed
+func (g *Game) rendering() bool {
+	return g.bandSample != nil || g.bandTiled
+}
+
+func (g *Game) bandInfo() string {
+	mode := "Whole Frames"
+	if g.banded {
+		mode = "Banded"
+	}
+	if g.rendering() {
+		rows := g.frame.Bounds().Dy() * g.frameKey.aaSamples
+		return fmt.Sprintf("Rendering: %s (%.0f%%)", mode, 100*float64(g.bandY)/float64(rows))
+	}
+	return "Rendering: " + mode
+}
+
+func fractalName(fractalType int) string {
+	switch fractalType {
+	case FractalMandelbrot:
+		return "Mandelbrot"
+	case FractalJulia:
+		return "Julia"
+	case FractalBurningShip:
+		return "Burning Ship"
+	case FractalTricorn:
+		return "Tricorn"
+	case FractalNewton:
+		return "Newton"
+	case FractalPhoenix:
+		return "Phoenix"
+	case FractalCeltic:
+		return "Celtic"
+	case FractalNova:
+		return "Nova"
+	case FractalTranscendental:
+		return "Transcendental"
+	case FractalBuffalo:
+		return "Buffalo"
+	case FractalMagnet1:
+		return "Magnet I"
+	case FractalCollatz:
+		return "Collatz"
+	case FractalLyapunov:
+		return "Lyapunov"
+	case FractalSpider:
+		return "Spider"
+	case FractalGlynn:
+		return "Glynn"
+	case FractalPerpMandelbrot:
+		return "Perpendicular Mandelbrot"
+	case FractalPerpBurningShip:
+		return "Perpendicular Burning Ship"
+	case FractalMandelbox:
+		return "Mandelbox"
+	case FractalManowar:
+		return "Manowar"
+	case FractalHalley:
+		return "Halley"
+	default:
+		return "Unknown"
+	}
 }
 
 func drawSidebar(screen *ebiten.Image, g *Game) {
-	sidebarWidth := 100
 	sidebarColor := color.RGBA{R: 50, G: 50, B: 50, A: 255}
 	sidebarRect := ebiten.NewImage(sidebarWidth, screen.Bounds().Dy())
 	sidebarRect.Fill(sidebarColor)
 
 	screen.DrawImage(sidebarRect, nil)
 
-	zoomSpeedX := 10
-	zoomSpeedY := 70
-	zoomSpeedHeight := 200
-
-	vector.DrawFilledRect(screen, float32(zoomSpeedX), float32(zoomSpeedY), 10, float32(zoomSpeedHeight), color.RGBA{200, 200, 200, 255}, false)
+	sliderColor := color.RGBA{200, 200, 200, 255}
+	handleColor := color.RGBA{255, 0, 0, 255}
 
 	// zoom speed
-	currentZoomSpeedY := zoomSpeedY + int((g.zoomSpeed/0.5)*float64(zoomSpeedHeight))
-	vector.DrawFilledRect(screen, float32(zoomSpeedX), float32(currentZoomSpeedY-5), 10, 10, color.RGBA{255, 0, 0, 255}, false)
+	vector.DrawFilledRect(screen, zoomSliderX, sliderTop, sliderWidth, sliderHeight, sliderColor, false)
+	zoomSpeedY := sliderTop + int((1-zoomSpeedSlider(g.zoomSpeed))/2*sliderHeight)
+	vector.DrawFilledRect(screen, zoomSliderX, float32(zoomSpeedY-5), sliderWidth, 10, handleColor, false)
+
+	// max iterations
+	vector.DrawFilledRect(screen, iterSliderX, sliderTop, sliderWidth, sliderHeight, sliderColor, false)
+	maxIterY := sliderTop + int(float64(g.maxIter-sliderMinIter)/(sliderMaxIter-sliderMinIter)*sliderHeight)
+	vector.DrawFilledRect(screen, iterSliderX, float32(maxIterY-5), sliderWidth, 10, handleColor, false)
 
 	// switch between fractals
 	buttonText := "Toggle Fractal"
@@ -182,57 +2000,273 @@ func drawSidebar(screen *ebiten.Image, g *Game) {
 	buttonY := 300
 	vector.DrawFilledRect(screen, float32(buttonX), float32(buttonY), float32(buttonWidth), float32(buttonHeight), color.RGBA{100, 100, 100, 255}, false)
 	text.Draw(screen, buttonText, basicfont.Face7x13, buttonX+5, buttonY+25, color.White)
+
+	// automatic iteration count
+	autoText := "Auto Iter: Off"
+	if g.autoIter {
+		autoText = "Auto Iter: On"
+	}
+	autoY := 350
+	vector.DrawFilledRect(screen, float32(buttonX), float32(autoY), float32(buttonWidth), float32(buttonHeight), color.RGBA{100, 100, 100, 255}, false)
+	text.Draw(screen, autoText, basicfont.Face7x13, buttonX+5, autoY+25, color.White)
+
+	// back to the default view
+	resetY := 400
+	vector.DrawFilledRect(screen, float32(buttonX), float32(resetY), float32(buttonWidth), float32(buttonHeight), color.RGBA{100, 100, 100, 255}, false)
+	text.Draw(screen, "Reset", basicfont.Face7x13, buttonX+5, resetY+25, color.White)
+}
+
+// drawCrosshair marks the cursor while it is over the fractal
+func drawCrosshair(screen *ebiten.Image, g *Game) {
+	x, y := ebiten.CursorPosition()
+	if x < sidebarWidth {
+		return
+	}
+	fx, fy := float32(x)+0.5, float32(y)+0.5
+	vector.StrokeLine(screen, fx-6, fy, fx+7, fy, 1, color.White, false)
+	vector.StrokeLine(screen, fx, fy-6, fx, fy+7, 1, color.White, false)
 }
 
-func drawInfo(screen *ebiten.Image, zoomSpeed, zoomLevel, centerX, centerY float64, fractalType int) {
+func drawInfo(screen *ebiten.Image, g *Game) {
 	myFont := basicfont.Face7x13
 
-	speedContent := fmt.Sprintf("Zoom Speed: %.3f", zoomSpeed)
+	speedContent := fmt.Sprintf("Zoom Speed: %.4f", g.zoomSpeed)
 	text.Draw(screen, speedContent, myFont, 10, 20, color.White)
 
-	levelContent := fmt.Sprintf("Zoom Level: %.2f", zoomLevel)
+	levelContent := fmt.Sprintf("Zoom Level: %.2f", g.zoom)
 	text.Draw(screen, levelContent, myFont, 10, 40, color.White)
 
-	centerContent := fmt.Sprintf("Center: (%.6f, %.6f)", centerX, centerY)
+	centerContent := fmt.Sprintf("Center: (%.6f, %.6f)", g.centerX, g.centerY)
 	text.Draw(screen, centerContent, myFont, 10, 60, color.White)
 
-	fractalName := "Fractal"
-	switch fractalType {
-	case FractalMandelbrot:
-		fractalName = "Mandelbrot"
-	case FractalJulia:
-		fractalName = "Julia"
-	default:
-		fractalName = "Unknown"
+	maxIterContent := fmt.Sprintf("Max Iter: %d", g.currentMaxIter())
+	if g.autoIter {
+		maxIterContent += " (auto)"
+	}
+
+	// status lines sit just right of the sidebar so they stay clear of its controls
+	status := []string{
+		fmt.Sprintf("Fractal: %s", fractalName(g.fractalType)),
+		fmt.Sprintf("Power: %d", g.power),
+		fmt.Sprintf("Escape Radius: %g", g.escapeRadius),
+		maxIterContent,
+		fmt.Sprintf("Palette: %s", palettes[g.paletteIndex].Name),
+		g.coloringInfo(),
+		g.colorCycleInfo(),
+		g.interiorInfo(),
+		g.ditherInfo(),
+		fmt.Sprintf("Blend: %s", colorSpaceName(g.colorSpace)),
+		fmt.Sprintf("Gamma: %.1f", g.gamma),
+		fmt.Sprintf("Color Density: %g", g.colorDensity),
+		fmt.Sprintf("Precision: %s", g.precisionName()),
+		g.qualityInfo(),
+		fmt.Sprintf("AA: %dx%d", g.aaSamples, g.aaSamples),
+		fmt.Sprintf("Pixel Step: %d", g.pixelStep),
+		g.bandInfo(),
+		g.accumInfo(),
+		fpsInfo(),
+		fmt.Sprintf("Render Time: %.1f ms", float64(g.renderTime.Microseconds())/1000),
+	}
+	if g.fractalType == FractalTranscendental {
+		status = append(status, fmt.Sprintf("Function: %s, c = (%g, %g)", transFuncName(g.transFunc), g.juliaX, g.juliaY))
+	} else if g.fractalType == FractalMandelbox {
+		status = append(status, fmt.Sprintf("Box Scale: %g", g.boxScale))
+	} else if usesJuliaConstant(g.fractalType) {
+		status = append(status, fmt.Sprintf("c = (%.6f, %.6f)", g.juliaX, g.juliaY))
+	}
+	if g.showPeriod && g.fractalType == FractalMandelbrot && g.power == 2 {
+		status = append(status, periodInfo(attractorPeriod(g.centerX, g.centerY)))
+	}
+	if x, y := ebiten.CursorPosition(); x >= sidebarWidth {
+		cx, cy := g.cursorToComplex(x, y)
+		status = append(status, fmt.Sprintf("Cursor: (%.6f, %.6f)", cx, cy))
+	}
+	rows := max((g.height-statusTop)/statusLineHeight, 1)
+	for i, line := range status {
+		x := sidebarWidth + 10 + i/rows*statusColumnWidth
+		text.Draw(screen, line, myFont, x, statusTop+i%rows*statusLineHeight, color.White)
+	}
+
+	// passing notices stack up in the top right, closely enough that all of
+	// them stay above the status list
+	type notice struct {
+		text string
+		clr  color.Color
+	}
+	var notices []notice
+	if g.paused {
+		notices = append(notices, notice{"PAUSED", color.White})
+	}
+	if g.precisionLost() {
+		notices = append(notices, notice{"Precision limit reached", color.RGBA{255, 0, 0, 255}})
+	}
+	if g.tour != nil {
+		notices = append(notices, notice{g.tourInfo(), color.White})
+	}
+	if time.Since(g.copiedAt) < copiedMessageTime {
+		notices = append(notices, notice{"Copied!", color.White})
 	}
+	for i, n := range notices {
+		text.Draw(screen, n.text, myFont, g.width-len(n.text)*7-10, 20+i*15, n.clr)
+	}
+
+	// progress of background renders stacks up from just above the minimap,
+	// out of the way of the status list
+	_, y := g.minimapPos()
+	y -= 20
+	if g.gif != nil {
+		frames := g.gif.frames.Load()
+		drawProgress(screen, fmt.Sprintf("Recording GIF: %d/%d", frames, gifFrames), float64(frames)/gifFrames, y)
+		y -= 30
+	}
+	if g.export != nil {
+		f := g.export.fraction()
+		drawProgress(screen, fmt.Sprintf("Exporting: %.0f%%", f*100), f, y)
+	}
+}
+
+// drawProgress draws a labelled progress bar at height y against the right
+// edge, fraction from 0 to 1
+func drawProgress(screen *ebiten.Image, label string, fraction float64, y int) {
+	x := screen.Bounds().Dx() - progressWidth - 10
+	text.Draw(screen, label, basicfont.Face7x13, x, y, color.White)
+	vector.DrawFilledRect(screen, float32(x), float32(y+5), progressWidth, 8, color.RGBA{100, 100, 100, 255}, false)
+	vector.DrawFilledRect(screen, float32(x), float32(y+5), float32(fraction*progressWidth), 8, color.RGBA{255, 0, 0, 255}, false)
+}
 
-	text.Draw(screen, fmt.Sprintf("Fractal: %s", fractalName), myFont, 10, 360, color.White)
+// Layout follows the window size so resizing shows more of the plane
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	g.width = max(outsideWidth, sidebarWidth+1)
+	g.height = max(outsideHeight, 1)
+	return g.width, g.height
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return 640, 480
+// parseCenter parses a complex plane coordinate written as "x,y", to the
+// double-double precision of the view center
+func parseCenter(s string) (dd, dd, error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return dd{}, dd{}, fmt.Errorf("%q is not of the form x,y", s)
+	}
+
+	x, err := parseDD(strings.TrimSpace(xs))
+	if err != nil {
+		return dd{}, dd{}, err
+	}
+	y, err := parseDD(strings.TrimSpace(ys))
+	if err != nil {
+		return dd{}, dd{}, err
+	}
+	return x, y, nil
 }
 
 func main() {
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		log.Printf("loading config: %v, using the defaults", err)
+	}
+
+	palettePath := flag.String("palette", "", "CSV file of r,g,b lines to use as the colour palette")
+	center := flag.String("center", "", "start centered on `x,y` in the complex plane")
+	zoom := flag.Float64("zoom", cfg.Zoom, "starting zoom level, at least 1")
+	width := flag.Int("width", cfg.Width, "window width in pixels, including the sidebar")
+	height := flag.Int("height", cfg.Height, "window height in pixels")
+	title := flag.String("title", "Fractals", "window title")
+	fps := flag.Int("fps", ebiten.DefaultTPS, "updates per second")
+	vsync := flag.Bool("vsync", true, "wait for the display's refresh before drawing")
+	flag.IntVar(&workerCount, "workers", workerCount, "number of render worker goroutines")
+	bench := flag.Int("bench", 0, "render the starting view `N` times without a window and report the timings")
+	flag.Parse()
+
 	game := &Game{
-		minX: -2.5,
-		maxX: 1.0,
-		minY: -1.5,
-		maxY: 1.5,
-		/* Center on Seahorse Valley
-		http://www.mrob.com/pub/muency/seahorsevalley.html
-		*/
-		centerX:    0.42884,
-		centerY:    -0.231345,
+		minX:       -2.5,
+		maxX:       1.0,
+		minY:       -1.5,
+		maxY:       1.5,
+		centerX:    cfg.CenterX,
+		centerY:    cfg.CenterY,
 		juliaX:     0.0,
 		juliaY:     0.0,
-		zoom:       0.0,  // Initial zoom level
-		zoomSpeed:  0.01, // Initial zoom speed
+		zoom:       cfg.Zoom,      // Initial zoom level
+		zoomSpeed:  cfg.ZoomSpeed, // Initial zoom speed
+		power:      2,
+		maxIter:    200,
+		aaSamples:  1,
+		pixelStep:  1,
+		quality:    QualityNormal,
+		gpu:        true,
 		lastUpdate: time.Now(),
+		width:      *width,
+		height:     *height,
+
+		colorCycleSpeed: 4,
+		escapeRadius:    2,
+		gamma:           1,
+		colorDensity:    1,
+		boxScale:        defaultBoxScale,
+		bookmarkIndex:   -1,
+		minibrotIndex:   -1,
+		crossfade:       cfg.Crossfade,
+		tourSeconds:     cfg.TourSeconds,
+	}
+
+	if *center != "" {
+		x, y, err := parseCenter(*center)
+		if err != nil {
+			log.Fatalf("invalid -center: %v", err)
+		}
+		game.setPreciseCenter(x, y)
+	}
+	if *zoom < 1 || math.IsInf(*zoom, 0) || math.IsNaN(*zoom) {
+		log.Fatalf("invalid -zoom: must be a finite number of at least 1, got %v", *zoom)
+	}
+	game.zoom = math.Min(*zoom, maxZoom)
+
+	bookmarks, err := LoadBookmarks(bookmarksPath)
+	if err != nil {
+		log.Printf("loading bookmarks: %v", err)
+	}
+	game.bookmarks = bookmarks
+
+	// the config can name a built-in palette, anything else is a file to load
+	if *palettePath == "" {
+		if i := paletteByName(cfg.Palette); i >= 0 {
+			game.paletteIndex = i
+		} else {
+			*palettePath = cfg.Palette
+		}
+	}
+	if *palettePath != "" {
+		colors, err := LoadPalette(*palettePath)
+		if err != nil {
+			log.Printf("loading palette: %v, using the default palette", err)
+		} else {
+			palettes = append(palettes, Palette{Name: filepath.Base(*palettePath), Colors: colors})
+			game.paletteIndex = len(palettes) - 1
+		}
+	}
+
+	if workerCount < 1 {
+		log.Fatalf("invalid -workers: must be at least 1, got %d", workerCount)
+	}
+	if *fps < 1 {
+		log.Fatalf("invalid -fps: must be at least 1, got %d", *fps)
+	}
+	if *width <= sidebarWidth || *height <= 0 {
+		log.Fatalf("invalid -width and -height: window size %dx%d too small", *width, *height)
+	}
+	game.visited[game.fractalType] = true
+
+	if *bench > 0 {
+		runBench(game, *bench)
+		return
 	}
 
-	ebiten.SetWindowSize(640, 480)
-	ebiten.SetWindowTitle("Fractals")
+	ebiten.SetWindowSize(*width, *height)
+	ebiten.SetWindowTitle(*title)
+	ebiten.SetTPS(*fps)
+	ebiten.SetVsyncEnabled(*vsync)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,150 @@
+package main
+
+import "testing"
+
+// testGame is the starting Mandelbrot view in a default sized window
+func testGame() *Game {
+	return &Game{
+		minX: -2.5, maxX: 1.0, minY: -1.5, maxY: 1.5,
+		centerX: defaultCenterX, centerY: defaultCenterY, zoom: 1,
+		power: 2, maxIter: 200, escapeRadius: 2, aaSamples: 1, pixelStep: 1, gamma: 1,
+		width: screenWidth, height: screenHeight,
+	}
+}
+
+func TestMandelbrot(t *testing.T) {
+	const maxIter = 200
+	tests := []struct {
+		name   string
+		cx, cy float64
+		inside bool
+	}{
+		{"origin", 0, 0, true},
+		{"period 2 bulb", -1, 0, true},
+		{"inside the cardioid", 0.2, 0, true},
+		{"far outside", 2, 2, false},
+		{"just outside", 0.26, 0, false},
+		{"seahorse valley", -0.75, 0.1, false},
+	}
+	for _, tt := range tests {
+		v := mandelbrot(tt.cx, tt.cy, 2, maxIter)
+		if inside := v >= maxIter; inside != tt.inside {
+			t.Errorf("%s: mandelbrot(%v, %v) = %v, inside %v, want %v", tt.name, tt.cx, tt.cy, v, inside, tt.inside)
+		}
+	}
+}
+
+func TestMandelbrotEscapesQuickly(t *testing.T) {
+	// (2, 2) is already past the radius after the first step
+	if v := mandelbrot(2, 2, 2, 200); v < 1 || v > 2 {
+		t.Errorf("mandelbrot(2, 2) = %v, want between 1 and 2", v)
+	}
+}
+
+func TestMultibrotPower2MatchesMandelbrot(t *testing.T) {
+	for _, c := range []struct{ x, y float64 }{{0, 0}, {-0.75, 0.1}, {0.3, 0.5}, {-1.8, 0}} {
+		if m, want := multibrot(c.x, c.y, 2, 2, 200), mandelbrot(c.x, c.y, 2, 200); m != want {
+			t.Errorf("multibrot(%v, %v, 2) = %v, want %v", c.x, c.y, m, want)
+		}
+	}
+}
+
+func TestJulia(t *testing.T) {
+	const maxIter = 200
+	// with c = 0 the julia set is the unit circle
+	tests := []struct {
+		x, y   float64
+		inside bool
+	}{
+		{0, 0, true},
+		{0.5, 0.5, true},
+		{0, -0.9, true},
+		{1.1, 0, false},
+		{-0.8, 0.8, false},
+	}
+	for _, tt := range tests {
+		v := julia(tt.x, tt.y, 0, 0, 2, false, maxIter)
+		if inside := v >= maxIter; inside != tt.inside {
+			t.Errorf("julia(%v, %v) = %v, inside %v, want %v", tt.x, tt.y, v, inside, tt.inside)
+		}
+	}
+}
+
+func TestSmoothIterContinuous(t *testing.T) {
+	// escaping just at the radius or a whole step later land on the same count
+	before := smoothIter(5, 4, 2, 2)
+	after := smoothIter(4, 2, 2, 2)
+	if d := before - after; d > 1e-9 || d < -1e-9 {
+		t.Errorf("smoothIter jumps by %v across an iteration", d)
+	}
+}
+
+func TestComputeFrame(t *testing.T) {
+	g := testGame()
+	g.centerX, g.centerY = -0.5, 0
+	const w, h = 64, 48
+	values := computeFrame(g, w, h)
+	if len(values) != w*h {
+		t.Fatalf("computeFrame gave %d values, want %d", len(values), w*h)
+	}
+	if v := values[h/2*w+w/2]; v < float64(g.maxIter) {
+		t.Errorf("center of the frame at (-0.5, 0) = %v, want inside", v)
+	}
+	if v := values[0]; v >= float64(g.maxIter) {
+		t.Errorf("corner of the frame = %v, want escaped", v)
+	}
+}
+
+func TestRenderFractalInStrips(t *testing.T) {
+	// a render split into strips colours exactly like the whole supersampled
+	// frame, edges across the strip boundaries included
+	g := testGame()
+	g.centerX, g.centerY = -0.75, 0.1
+	g.zoom = 20
+	g.aaSamples = 2
+	g.edges = true
+	const w, h = 48, 36
+	want := make([]byte, w*h*4)
+	g.colorFrame(want, computeFrame(g, w*2, h*2), w, h, 2, g.currentMaxIter())
+
+	defer func(v int) { stripValues = v }(stripValues)
+	stripValues = w * 2 * 2 * 5 // five pixel rows a strip, the last one short
+	got := make([]byte, w*h*4)
+	g.renderFractal(got, w, h)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pixel (%d, %d) = %v, want %v", i/4%w, i/4/w, got[i/4*4:i/4*4+4], want[i/4*4:i/4*4+4])
+		}
+	}
+}
+
+func TestTiledFrameInRows(t *testing.T) {
+	// a tiled frame put together a row of tiles at a time holds the value of
+	// the grid point under each sample
+	g := testGame()
+	g.centerX, g.centerY = -0.75, 0.1
+	g.zoom = 20
+	const w, h = 150, 100
+	values := make([]float64, w*h)
+	g.prepareTiles(w, h)
+	bands := 0
+	for y := 0; y < h; bands++ {
+		y = g.computeTileRow(values, w, h, y)
+	}
+	if bands < h/tileSize+1 {
+		t.Errorf("frame took %d rows of tiles, want at least %d", bands, h/tileSize+1)
+	}
+
+	key := g.tiles.key
+	originX, originY := g.tileOrigin()
+	sample := g.pointSampler(g.currentMaxIter())
+	for y := 0; y < h; y += 7 {
+		for x := 0; x < w; x += 7 {
+			cx := float64(originX+int64(x)) * key.pixelSizeX
+			cy := float64(originY+int64(y)) * key.pixelSizeY
+			if want := sample(cx, cy); values[y*w+x] != want {
+				t.Errorf("sample (%d, %d) = %v, want %v", x, y, values[y*w+x], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import "math"
+
+// the 2D mandelbox slice, drawn mandelboxSpan times smaller than the plane so
+// the whole box fits in the view at zoom 1
+const (
+	mandelboxSpan   = 4
+	mandelboxRadius = 16.0 // orbits past this radius escape
+	mandelboxMinR2  = 0.25 // squared radius inside which the ball fold scales by a fixed amount
+	defaultBoxScale = 2
+	maxBoxScale     = 3
+	minBoxScale     = 1.5 // below this the set fills the plane or vanishes
+	boxScaleStep    = 0.25
+)
+
+// mandelbox iterates z -> scale*ballFold(boxFold(z)) + c from z = c. The box
+// fold reflects each coordinate back inside [-1, 1], the ball fold inverts
+// points inside the unit circle.
+func mandelbox(x, y, scale float64, maxIter int) float64 {
+	cx, cy := x, y
+	bailout := mandelboxRadius * mandelboxRadius
+
+	for iteration := 0; iteration < maxIter; iteration++ {
+		x, y = boxFold(x), boxFold(y)
+
+		r2 := x*x + y*y
+		k := scale
+		if r2 < mandelboxMinR2 {
+			k /= mandelboxMinR2
+		} else if r2 < 1 {
+			k /= r2
+		}
+		x = k*x + cx
+		y = k*y + cy
+
+		// the orbit grows by |scale| a step once it escapes, so the
+		// fraction of a step past the radius smooths the count
+		if mag := x*x + y*y; mag > bailout {
+			over := math.Log(math.Sqrt(mag)/mandelboxRadius) / math.Log(math.Abs(scale))
+			return float64(iteration) + 1 + math.Max(1-over, 0)
+		}
+	}
+	return interiorValue(x, y, maxIter)
+}
+
+func boxFold(v float64) float64 {
+	if v > 1 {
+		return 2 - v
+	}
+	if v < -1 {
+		return -2 - v
+	}
+	return v
+}
+
+// stepBoxScale moves the mandelbox scale by step, jumping over the scales
+// between -minBoxScale and minBoxScale
+func stepBoxScale(scale, step float64) float64 {
+	scale += step
+	if math.Abs(scale) < minBoxScale {
+		scale = math.Copysign(minBoxScale, step)
+	}
+	return math.Max(-maxBoxScale, math.Min(scale, maxBoxScale))
+}
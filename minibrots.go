@@ -0,0 +1,49 @@
+package main
+
+import "math"
+
+// Minibrot is a known small copy of the Mandelbrot set
+type Minibrot struct {
+	Period           int
+	CenterX, CenterY float64
+	Size             float64 // approximate diameter
+}
+
+// minibrots along the real axis antenna, from the main body out to the tip at -2
+var minibrots = []Minibrot{
+	{3, -1.75487766624669, 0, 0.019},
+	{5, -1.86078252220485, 0, 7.8e-4},
+	{7, -1.88480357158668, 0, 4.38e-5},
+	{4, -1.94079980652948, 0, 9.92e-4},
+	{7, -1.9537058942844, 0, 1.57e-5},
+	{5, -1.98542425305421, 0, 5.82e-5},
+	{6, -1.99637613771119, 0, 3.56e-6},
+	{7, -1.99909568232702, 0, 2.21e-7},
+	{8, -1.99977404869373, 0, 1.38e-8},
+}
+
+// a snapped minibrot spans 1/minibrotFrame of the view
+const minibrotFrame = 6
+
+// nextMinibrot snaps to the minibrot nearest the center, or to the next one
+// along the antenna when the view is already on one
+func (g *Game) nextMinibrot() {
+	i := g.minibrotIndex
+	if i >= 0 && g.fractalType == FractalMandelbrot && g.centerX == minibrots[i].CenterX && g.centerY == minibrots[i].CenterY {
+		i = (i + 1) % len(minibrots)
+	} else {
+		i = 0
+		for j, m := range minibrots {
+			if math.Hypot(m.CenterX-g.centerX, m.CenterY-g.centerY) < math.Hypot(minibrots[i].CenterX-g.centerX, minibrots[i].CenterY-g.centerY) {
+				i = j
+			}
+		}
+	}
+
+	m := minibrots[i]
+	g.minibrotIndex = i
+	g.switchFractal(FractalMandelbrot)
+	g.power = 2
+	g.setCenter(m.CenterX, m.CenterY)
+	g.zoom = math.Max(1, math.Min((g.maxX-g.minX)/(minibrotFrame*m.Size), maxZoom))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// the minimap shows the whole set in the bottom right corner
+const (
+	minimapWidth   = 140
+	minimapHeight  = 120
+	minimapMargin  = 10
+	minimapMaxIter = 100
+)
+
+// minimapPos is the top left corner of the minimap on the window
+func (g *Game) minimapPos() (int, int) {
+	return g.width - minimapWidth - minimapMargin, g.height - minimapHeight - minimapMargin
+}
+
+func (g *Game) inMinimap(x, y int) bool {
+	mx, my := g.minimapPos()
+	return x >= mx && x < mx+minimapWidth && y >= my && y < my+minimapHeight
+}
+
+// minimapToComplex maps a window position over the minimap to its point in the plane
+func (g *Game) minimapToComplex(x, y int) (float64, float64) {
+	mx, my := g.minimapPos()
+	cx := g.minX + (g.maxX-g.minX)*float64(x-mx)/minimapWidth
+	cy := g.minY + (g.maxY-g.minY)*float64(y-my)/minimapHeight
+	return cx, cy
+}
+
+// minimapImage renders the thumbnail on first use, it never changes after that
+func (g *Game) minimapImage() *ebiten.Image {
+	if g.minimap != nil {
+		return g.minimap
+	}
+
+	pixels := make([]byte, minimapWidth*minimapHeight*4)
+	for y := 0; y < minimapHeight; y++ {
+		for x := 0; x < minimapWidth; x++ {
+			cx := g.minX + (g.maxX-g.minX)*float64(x)/minimapWidth
+			cy := g.minY + (g.maxY-g.minY)*float64(y)/minimapHeight
+			c := getColor(mandelbrot(cx, cy, 2, minimapMaxIter), minimapMaxIter, colorMapping, 1, 0, blendStyle{})
+			i := (y*minimapWidth + x) * 4
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = c.R, c.G, c.B, 255
+		}
+	}
+	g.minimap = ebiten.NewImage(minimapWidth, minimapHeight)
+	g.minimap.WritePixels(pixels)
+	return g.minimap
+}
+
+// drawMinimap draws the thumbnail with the visible region outlined on it,
+// or a dot at the center once that region is too small to see
+func drawMinimap(screen *ebiten.Image, g *Game) {
+	mx, my := g.minimapPos()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(mx), float64(my))
+	screen.DrawImage(g.minimapImage(), op)
+	vector.StrokeRect(screen, float32(mx), float32(my), minimapWidth, minimapHeight, 1, color.RGBA{200, 200, 200, 255}, false)
+
+	scaleX := minimapWidth / (g.maxX - g.minX)
+	scaleY := minimapHeight / (g.maxY - g.minY)
+	minX, maxX, minY, maxY := g.viewBounds()
+	w, h := (maxX-minX)*scaleX, (maxY-minY)*scaleY
+	marker := color.RGBA{255, 0, 0, 255}
+	if w >= 3 && h >= 3 {
+		vector.StrokeRect(screen, float32(float64(mx)+(minX-g.minX)*scaleX), float32(float64(my)+(minY-g.minY)*scaleY), float32(w), float32(h), 1, marker, false)
+		return
+	}
+	x := float64(mx) + (g.centerX-g.minX)*scaleX
+	y := float64(my) + (g.centerY-g.minY)*scaleY
+	vector.DrawFilledRect(screen, float32(x)-1.5, float32(y)-1.5, 3, 3, marker, false)
+}
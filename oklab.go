@@ -0,0 +1,96 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// colour spaces palette entries can be blended in
+const (
+	ColorSpaceRGB   = iota // straight lines between the sRGB values
+	ColorSpaceOklab        // perceptually even blends, without muddy midtones
+
+	colorSpaceCount // number of colour spaces, keep last
+)
+
+func colorSpaceName(space int) string {
+	switch space {
+	case ColorSpaceRGB:
+		return "RGB"
+	case ColorSpaceOklab:
+		return "Oklab"
+	default:
+		return "Unknown"
+	}
+}
+
+// srgbToLinear undoes the sRGB transfer curve of each 8 bit channel value
+var srgbToLinear = func() (table [256]float64) {
+	for i := range table {
+		v := float64(i) / 255
+		if v <= 0.04045 {
+			table[i] = v / 12.92
+		} else {
+			table[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return table
+}()
+
+// linearToSRGB applies the sRGB transfer curve, returning 0 to 255
+func linearToSRGB(v float64) float64 {
+	v = math.Max(0, math.Min(v, 1))
+	if v <= 0.0031308 {
+		return 255 * 12.92 * v
+	}
+	return 255 * (1.055*math.Pow(v, 1/2.4) - 0.055)
+}
+
+// oklab is a colour in the Oklab space, https://bottosson.github.io/posts/oklab/
+type oklab struct {
+	l, a, b float64
+}
+
+func toOklab(c color.RGBA) oklab {
+	r, g, b := srgbToLinear[c.R], srgbToLinear[c.G], srgbToLinear[c.B]
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return oklab{
+		l: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		a: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		b: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// rgb converts back to sRGB, with every channel from 0 to 255
+func (c oklab) rgb() (r, g, b float64) {
+	l := c.l + 0.3963377774*c.a + 0.2158037573*c.b
+	m := c.l - 0.1055613458*c.a - 0.0638541728*c.b
+	s := c.l - 0.0894841775*c.a - 1.2914855480*c.b
+	l, m, s = l*l*l, m*m*m, s*s*s
+	r = linearToSRGB(4.0767416621*l - 3.3077115913*m + 0.2309699292*s)
+	g = linearToSRGB(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s)
+	b = linearToSRGB(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s)
+	return r, g, b
+}
+
+// lerpOklab is lerpColor with the blend made in Oklab. Alpha stays linear.
+func lerpOklab(a, b color.RGBA, t, dither float64) color.RGBA {
+	ca, cb := toOklab(a), toOklab(b)
+	mixed := oklab{
+		l: ca.l + (cb.l-ca.l)*t,
+		a: ca.a + (cb.a-ca.a)*t,
+		b: ca.b + (cb.b-ca.b)*t,
+	}
+	// the round trip through Oklab comes back a hair off whole values, which
+	// would otherwise round down a step
+	const slack = 1e-3
+	r, g, bl := mixed.rgb()
+	return color.RGBA{
+		R: uint8(math.Min(r+dither+slack, 255)),
+		G: uint8(math.Min(g+dither+slack, 255)),
+		B: uint8(math.Min(bl+dither+slack, 255)),
+		A: uint8(float64(a.A) + (float64(b.A)-float64(a.A))*t + dither),
+	}
+}
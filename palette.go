@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Palette struct {
+	Name   string
+	Colors []color.RGBA
+}
+
+// palettes that can be cycled through with P
+var palettes = []Palette{
+	{Name: "Wikipedia", Colors: colorMapping},
+	{Name: "Grayscale", Colors: []color.RGBA{
+		{0, 0, 0, 255},
+		{64, 64, 64, 255},
+		{128, 128, 128, 255},
+		{192, 192, 192, 255},
+		{255, 255, 255, 255},
+		{192, 192, 192, 255},
+		{128, 128, 128, 255},
+		{64, 64, 64, 255},
+	}},
+	{Name: "Fire", Colors: []color.RGBA{
+		{20, 0, 0, 255},
+		{90, 0, 0, 255},
+		{160, 20, 0, 255},
+		{220, 70, 0, 255},
+		{255, 140, 0, 255},
+		{255, 210, 40, 255},
+		{255, 255, 160, 255},
+		{255, 210, 40, 255},
+		{220, 70, 0, 255},
+		{120, 10, 0, 255},
+	}},
+	{Name: "Ocean", Colors: []color.RGBA{
+		{0, 10, 30, 255},
+		{0, 30, 70, 255},
+		{0, 60, 120, 255},
+		{0, 110, 160, 255},
+		{30, 160, 190, 255},
+		{120, 210, 220, 255},
+		{220, 245, 250, 255},
+		{120, 210, 220, 255},
+		{30, 160, 190, 255},
+		{0, 60, 120, 255},
+	}},
+}
+
+// palette returns the colours of the selected palette
+func (g *Game) palette() []color.RGBA {
+	return palettes[g.paletteIndex].Colors
+}
+
+// paletteByName returns the index of the palette called name, or -1
+func paletteByName(name string) int {
+	for i, p := range palettes {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// LoadPalette reads a palette from a CSV file with one r,g,b line (0-255) per colour.
+// Lines starting with # are ignored.
+func LoadPalette(path string) ([]color.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = 3
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("palette has no colours")
+	}
+
+	colors := make([]color.RGBA, len(records))
+	for i, record := range records {
+		var rgb [3]uint8
+		for j, field := range record {
+			v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			rgb[j] = uint8(v)
+		}
+		colors[i] = color.RGBA{rgb[0], rgb[1], rgb[2], 255}
+	}
+	return colors, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// the julia picker shows the whole julia set for the point under the cursor
+// next to the view, at this zoom around the origin
+const pickerJuliaZoom = 1.0
+
+// pickerJulia is the view drawn on the right half of the julia picker
+func (g *Game) pickerJulia() *Game {
+	julia := *g
+	julia.fractalType = FractalJulia
+	julia.setCenter(0, 0)
+	julia.zoom = pickerJuliaZoom
+	return &julia
+}
+
+// pickJulia follows the cursor while it is over the view on the left half
+func (g *Game) pickJulia() {
+	x, y := ebiten.CursorPosition()
+	w, h := g.viewSize()
+	if x >= sidebarWidth && x < sidebarWidth+w && y >= 0 && y < h {
+		g.juliaX, g.juliaY = g.cursorToComplex(x, y)
+	}
+}
+
+// drawPicker draws the julia set of the picked point right of the w*h view,
+// rendering it again only when the constant or its colours change
+func (g *Game) drawPicker(screen *ebiten.Image, w, h int) {
+	julia := g.pickerJulia()
+	view := julia.view()
+	look := g.look()
+	if g.pickerFrame == nil || g.pickerFrame.Bounds().Dx() != w || g.pickerFrame.Bounds().Dy() != h {
+		g.pickerFrame = ebiten.NewImage(w, h)
+		g.pickerPixels = make([]byte, w*h*4)
+		g.pickerValid = false
+	}
+	if !g.pickerValid || view != g.pickerView || look != g.pickerLook {
+		julia.renderFractal(g.pickerPixels, w, h)
+		g.pickerFrame.WritePixels(g.pickerPixels)
+		g.pickerView, g.pickerLook = view, look
+		g.pickerValid = true
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(sidebarWidth+w), 0)
+	screen.DrawImage(g.pickerFrame, op)
+}
@@ -0,0 +1,56 @@
+package main
+
+// render quality presets, each sets the iteration count, anti-aliasing and
+// pixel step together
+const (
+	QualityDraft = iota // fast and coarse, for finding a view
+	QualityNormal
+	QualityHigh
+	QualityUltra // for stills
+
+	qualityCount // number of quality presets, keep last
+)
+
+type qualityPreset struct {
+	maxIter, aaSamples, pixelStep int
+}
+
+var qualityPresets = [qualityCount]qualityPreset{
+	QualityDraft:  {100, 1, 4},
+	QualityNormal: {200, 1, 1},
+	QualityHigh:   {1000, 2, 1},
+	QualityUltra:  {sliderMaxIter, 4, 1},
+}
+
+func qualityName(quality int) string {
+	switch quality {
+	case QualityDraft:
+		return "Draft"
+	case QualityNormal:
+		return "Normal"
+	case QualityHigh:
+		return "High"
+	case QualityUltra:
+		return "Ultra"
+	default:
+		return "Unknown"
+	}
+}
+
+// nextQuality switches to the next preset. The slider's iteration count is
+// what a preset sets, so auto iterations are turned off.
+func (g *Game) nextQuality() {
+	g.quality = (g.quality + 1) % qualityCount
+	p := qualityPresets[g.quality]
+	g.maxIter, g.aaSamples, g.pixelStep = p.maxIter, p.aaSamples, p.pixelStep
+	g.autoIter = false
+}
+
+// qualityInfo names the preset, or Custom once any of its settings were changed by hand
+func (g *Game) qualityInfo() string {
+	p := qualityPresets[g.quality]
+	if g.autoIter || g.maxIter != p.maxIter || g.aaSamples != p.aaSamples || g.pixelStep != p.pixelStep {
+		return "Quality: Custom"
+	}
+	return "Quality: " + qualityName(g.quality)
+}
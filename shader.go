@@ -0,0 +1,90 @@
+package main
+
+import (
+	_ "embed"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+//go:embed fractal.kage
+var fractalShaderSource []byte
+
+// float32 loses neighbouring pixels well before float64, so the shader only
+// draws shallow zooms and the cpu renderer takes over past this
+const gpuMaxZoom = 1e4
+
+// the shader's palette uniform holds at most this many colours
+const gpuPaletteSize = 64
+
+var (
+	fractalShader    *ebiten.Shader
+	fractalShaderErr error
+)
+
+// shader compiles the fractal shader on first use. A shader that fails to
+// compile is only reported once, drawing then stays on the cpu.
+func shader() *ebiten.Shader {
+	if fractalShader == nil && fractalShaderErr == nil {
+		fractalShader, fractalShaderErr = ebiten.NewShader(fractalShaderSource)
+		if fractalShaderErr != nil {
+			log.Printf("compiling fractal shader: %v, rendering on the cpu", fractalShaderErr)
+		}
+	}
+	return fractalShader
+}
+
+// useGPU reports whether the current view can be drawn by the shader, which
+// only knows the plain escape-time colouring of the quadratic Mandelbrot and Julia
+func (g *Game) useGPU() bool {
+	switch {
+	case !g.gpu:
+		return false
+	case g.fractalType != FractalMandelbrot && g.fractalType != FractalJulia:
+		return false
+	case g.fractalType == FractalMandelbrot && g.power != 2:
+		return false
+	case g.colorMode != ColorDirect || g.interiorColoring || g.showSettle() || g.biomorph || g.accumulate || g.heatmap || g.edges || g.dither || g.colorSpace != ColorSpaceRGB || g.aaSamples != 1 || g.pixelStep != 1:
+		return false
+	case g.zoom > gpuMaxZoom || len(g.palette()) > gpuPaletteSize:
+		return false
+	}
+	return shader() != nil
+}
+
+// drawGPU renders the view straight onto screen, right of the sidebar
+func (g *Game) drawGPU(screen *ebiten.Image, w, h int) {
+	minX, maxX, minY, maxY := g.viewBounds()
+
+	palette := g.palette()
+	colors := make([]float32, gpuPaletteSize*4)
+	for i, c := range palette {
+		colors[i*4] = float32(c.R) / 255
+		colors[i*4+1] = float32(c.G) / 255
+		colors[i*4+2] = float32(c.B) / 255
+		colors[i*4+3] = float32(c.A) / 255
+	}
+
+	var julia float32
+	if g.fractalType == FractalJulia {
+		julia = 1
+	}
+
+	op := &ebiten.DrawRectShaderOptions{}
+	op.GeoM.Translate(sidebarWidth, 0)
+	op.Uniforms = map[string]any{
+		"ViewMin":       []float32{float32(minX), float32(minY)},
+		"PixelSize":     []float32{float32((maxX - minX) / float64(w)), float32((maxY - minY) / float64(h))},
+		"Origin":        []float32{sidebarWidth, 0},
+		"MaxIter":       float32(g.currentMaxIter()),
+		"EscapeRadius":  float32(g.escapeRadius),
+		"Julia":         julia,
+		"JuliaC":        []float32{float32(g.juliaX), float32(g.juliaY)},
+		"PaletteLen":    float32(len(palette)),
+		"PaletteOffset": float32(g.paletteOffset),
+		"ColorDensity":  float32(g.colorDensity),
+		"Palette":       colors,
+		"Gamma":         float32(g.gamma),
+	}
+	screen.DrawRectShader(w, h, shader(), op)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"time"
+)
+
+// statePath is where F5 saves the scene and F9 loads it from
+const statePath = "scene.fractal"
+
+// State is everything needed to recreate a scene exactly, as saved in a .fractal file
+type State struct {
+	FractalType      int       `json:"fractalType"`
+	TransFunc        int       `json:"transFunc"`
+	CenterX          float64   `json:"centerX"`
+	CenterY          float64   `json:"centerY"`
+	CenterLoX        float64   `json:"centerLoX,omitempty"` // lo parts of the double-double center
+	CenterLoY        float64   `json:"centerLoY,omitempty"`
+	JuliaX           float64   `json:"juliaX"`
+	JuliaY           float64   `json:"juliaY"`
+	Zoom             float64   `json:"zoom"`
+	ZoomSpeed        float64   `json:"zoomSpeed"`
+	Power            int       `json:"power"`
+	EscapeRadius     float64   `json:"escapeRadius"`
+	MaxIter          int       `json:"maxIter"`
+	AutoIter         bool      `json:"autoIter"`
+	Palette          string    `json:"palette"`
+	ColorMode        int       `json:"colorMode"`
+	AASamples        int       `json:"aaSamples"`
+	TrapShape        TrapShape `json:"trapShape"`
+	InteriorColoring bool      `json:"interiorColoring"`
+	ColorCycle       bool      `json:"colorCycle"`
+	ColorCycleSpeed  float64   `json:"colorCycleSpeed"`
+	PaletteOffset    float64   `json:"paletteOffset"`
+	Gamma            float64   `json:"gamma"`
+	AnimateJulia     bool      `json:"animateJulia"`
+	JuliaAngle       float64   `json:"juliaAngle"`
+	GPU              bool      `json:"gpu"`
+	JuliaPicker      bool      `json:"juliaPicker"`
+	Heatmap          bool      `json:"heatmap"`
+	Dither           bool      `json:"dither"`
+	PixelStep        int       `json:"pixelStep"`
+	ColorSpace       int       `json:"colorSpace"`
+	Edges            bool      `json:"edges"`
+	InteriorSettle   bool      `json:"interiorSettle"`
+	ColorDensity     float64   `json:"colorDensity"`
+	BoxScale         float64   `json:"boxScale"`
+	Biomorph         bool      `json:"biomorph"`
+	Quality          int       `json:"quality"`
+}
+
+func (g *Game) state() State {
+	return State{
+		FractalType:      g.fractalType,
+		TransFunc:        g.transFunc,
+		CenterX:          g.centerX,
+		CenterY:          g.centerY,
+		CenterLoX:        g.centerLoX,
+		CenterLoY:        g.centerLoY,
+		JuliaX:           g.juliaX,
+		JuliaY:           g.juliaY,
+		Zoom:             g.zoom,
+		ZoomSpeed:        g.zoomSpeed,
+		Power:            g.power,
+		EscapeRadius:     g.escapeRadius,
+		MaxIter:          g.maxIter,
+		AutoIter:         g.autoIter,
+		Palette:          palettes[g.paletteIndex].Name,
+		ColorMode:        g.colorMode,
+		AASamples:        g.aaSamples,
+		TrapShape:        g.trapShape,
+		InteriorColoring: g.interiorColoring,
+		ColorCycle:       g.colorCycle,
+		ColorCycleSpeed:  g.colorCycleSpeed,
+		PaletteOffset:    g.paletteOffset,
+		Gamma:            g.gamma,
+		AnimateJulia:     g.animateJulia,
+		JuliaAngle:       g.juliaAngle,
+		GPU:              g.gpu,
+		JuliaPicker:      g.juliaPicker,
+		Heatmap:          g.heatmap,
+		Dither:           g.dither,
+		PixelStep:        g.pixelStep,
+		ColorSpace:       g.colorSpace,
+		Edges:            g.edges,
+		InteriorSettle:   g.interiorSettle,
+		ColorDensity:     g.colorDensity,
+		BoxScale:         g.boxScale,
+		Biomorph:         g.biomorph,
+		Quality:          g.quality,
+	}
+}
+
+// SaveState writes the current scene to path as JSON
+func (g *Game) SaveState(path string) error {
+	data, err := json.MarshalIndent(g.state(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadState replaces the current scene with the one saved at path. Nothing
+// changes if the file can't be read or holds values the controls couldn't set.
+func (g *Game) LoadState(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// fields missing from scenes saved before they were added keep these
+	s := State{PixelStep: 1, ColorDensity: 1, BoxScale: defaultBoxScale, Quality: QualityNormal}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	g.fractalType, g.transFunc = s.FractalType, s.TransFunc
+	// the loaded view stands in for the default one switchFractal would show first
+	g.visited[g.fractalType] = true
+	g.setPreciseCenter(twoSum(s.CenterX, s.CenterLoX), twoSum(s.CenterY, s.CenterLoY))
+	g.juliaX, g.juliaY = s.JuliaX, s.JuliaY
+	g.zoom, g.zoomSpeed = s.Zoom, s.ZoomSpeed
+	g.power, g.escapeRadius = s.Power, s.EscapeRadius
+	g.maxIter, g.autoIter = s.MaxIter, s.AutoIter
+	g.colorMode, g.aaSamples = s.ColorMode, s.AASamples
+	g.trapShape, g.interiorColoring = s.TrapShape, s.InteriorColoring
+	g.colorCycle, g.colorCycleSpeed = s.ColorCycle, s.ColorCycleSpeed
+	g.gamma = s.Gamma
+	g.animateJulia, g.juliaAngle = s.AnimateJulia, s.JuliaAngle
+	g.gpu, g.juliaPicker = s.GPU, s.JuliaPicker
+	g.heatmap = s.Heatmap
+	g.dither = s.Dither
+	g.pixelStep = s.PixelStep
+	g.colorSpace = s.ColorSpace
+	g.edges = s.Edges
+	g.interiorSettle = s.InteriorSettle
+	g.colorDensity = s.ColorDensity
+	g.boxScale = s.BoxScale
+	g.biomorph = s.Biomorph
+	g.quality = s.Quality
+
+	// a palette loaded from a file on another machine may not be here
+	if i := paletteByName(s.Palette); i >= 0 {
+		g.paletteIndex = i
+	} else {
+		log.Printf("%s: no palette named %q, keeping %s", path, s.Palette, palettes[g.paletteIndex].Name)
+	}
+	g.paletteOffset = math.Mod(s.PaletteOffset, float64(len(g.palette())))
+
+	// runtime state starts over as if the scene had just been opened
+	g.lastUpdate = time.Now()
+	g.dragging = false
+	g.viewChangedAt = time.Now()
+	g.historyPending = true
+	g.frameValid = false
+	g.pickerValid = false
+	return nil
+}
+
+// validate checks s against the ranges the controls keep the game in
+func (s State) validate() error {
+	switch {
+	case s.FractalType < 0 || s.FractalType >= fractalCount:
+		return fmt.Errorf("unknown fractal type %d", s.FractalType)
+	case s.TransFunc < 0 || s.TransFunc >= transFuncCount:
+		return fmt.Errorf("unknown transcendental function %d", s.TransFunc)
+	case !finite(s.CenterX) || !finite(s.CenterY) || !finite(s.CenterLoX) || !finite(s.CenterLoY) || !finite(s.JuliaX) || !finite(s.JuliaY):
+		return fmt.Errorf("coordinates must be finite")
+	case !finite(s.Zoom) || s.Zoom < 1 || s.Zoom > maxZoom:
+		return fmt.Errorf("zoom %v out of range", s.Zoom)
+	case !finite(s.ZoomSpeed) || math.Abs(s.ZoomSpeed) > maxZoomSpeed:
+		return fmt.Errorf("zoom speed %v out of range", s.ZoomSpeed)
+	case s.Power < 2 || s.Power > 8:
+		return fmt.Errorf("power %d out of range", s.Power)
+	case !finite(s.EscapeRadius) || s.EscapeRadius < 2 || s.EscapeRadius > 1024:
+		return fmt.Errorf("escape radius %v out of range", s.EscapeRadius)
+	case s.MaxIter < 1:
+		return fmt.Errorf("max iter %d out of range", s.MaxIter)
+	case s.ColorMode < 0 || s.ColorMode >= colorModeCount:
+		return fmt.Errorf("unknown colour mode %d", s.ColorMode)
+	case s.AASamples != 1 && s.AASamples != 2 && s.AASamples != 4:
+		return fmt.Errorf("AA must be 1, 2 or 4, got %d", s.AASamples)
+	case s.TrapShape < 0 || s.TrapShape >= trapShapeCount:
+		return fmt.Errorf("unknown trap shape %d", s.TrapShape)
+	case !finite(s.ColorCycleSpeed) || s.ColorCycleSpeed < 1 || s.ColorCycleSpeed > 16:
+		return fmt.Errorf("colour cycle speed %v out of range", s.ColorCycleSpeed)
+	case !finite(s.PaletteOffset) || s.PaletteOffset < 0:
+		return fmt.Errorf("palette offset %v out of range", s.PaletteOffset)
+	case !finite(s.Gamma) || s.Gamma < 0.2 || s.Gamma > 5:
+		return fmt.Errorf("gamma %v out of range", s.Gamma)
+	case !finite(s.JuliaAngle):
+		return fmt.Errorf("julia angle must be finite")
+	case s.PixelStep != 1 && s.PixelStep != 2 && s.PixelStep != 4 && s.PixelStep != 8:
+		return fmt.Errorf("pixel step must be 1, 2, 4 or 8, got %d", s.PixelStep)
+	case s.ColorSpace < 0 || s.ColorSpace >= colorSpaceCount:
+		return fmt.Errorf("unknown colour space %d", s.ColorSpace)
+	case !finite(s.ColorDensity) || s.ColorDensity < minColorDensity || s.ColorDensity > maxColorDensity:
+		return fmt.Errorf("colour density %v out of range", s.ColorDensity)
+	case !finite(s.BoxScale) || math.Abs(s.BoxScale) < minBoxScale || math.Abs(s.BoxScale) > maxBoxScale:
+		return fmt.Errorf("mandelbox scale %v out of range", s.BoxScale)
+	case s.Quality < 0 || s.Quality >= qualityCount:
+		return fmt.Errorf("unknown quality preset %d", s.Quality)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import "math"
+
+// tiles are tileSize*tileSize samples
+const tileSize = 64
+
+// past this many tiles the cache starts over, about 32 MB of values
+const maxTiles = 1024
+
+// tileCoord is the position of a tile on the grid of samples covering the
+// whole plane, tile (0, 0) starting at the origin
+type tileCoord struct {
+	x, y int64
+}
+
+// tileSetKey is everything the values of a tile depend on besides its position
+type tileSetKey struct {
+	view                 viewState // with the center zeroed
+	pixelSizeX           float64
+	pixelSizeY           float64
+	valueKind, trapShape int
+	settle               bool
+}
+
+// tileCache keeps the values of recently rendered tiles, so panning only
+// computes the tiles that come into view
+type tileCache struct {
+	key   tileSetKey
+	tiles map[tileCoord][]float64
+}
+
+// canTile reports whether the frame can be put together from tiles. The
+// deep zoom samplers work relative to the view center instead of a fixed grid.
+func (g *Game) canTile() bool {
+	return !g.useBigFloat() && !g.useDD()
+}
+
+func (g *Game) tileSetKey(w, h int) tileSetKey {
+	view := g.view()
+	view.centerX, view.centerY = 0, 0
+	view.centerLoX, view.centerLoY = 0, 0
+	// from the extent rather than the bounds, which round differently as the center moves
+	width, height := g.viewExtent()
+	return tileSetKey{
+		view:       view,
+		pixelSizeX: width / float64(w),
+		pixelSizeY: height / float64(h),
+		valueKind:  g.valueKind(),
+		trapShape:  int(g.trapShape),
+		settle:     g.showSettle(),
+	}
+}
+
+// tilesValid reports whether the cached tiles still hold for a w*h frame of the view
+func (g *Game) tilesValid(w, h int) bool {
+	return g.tiles.tiles != nil && g.tiles.key == g.tileSetKey(w, h)
+}
+
+// prepareTiles readies the tile cache for a w*h frame of the view, starting
+// it over when the view's tiles no longer hold or it has grown too big
+func (g *Game) prepareTiles(w, h int) {
+	key := g.tileSetKey(w, h)
+	if g.tiles.tiles == nil || g.tiles.key != key || len(g.tiles.tiles) > maxTiles {
+		g.tiles = tileCache{key: key, tiles: make(map[tileCoord][]float64)}
+	}
+}
+
+// tileOrigin is the grid position of the top left sample of the frame
+func (g *Game) tileOrigin() (int64, int64) {
+	minX, _, minY, _ := g.viewBounds()
+	return int64(math.Floor(minX / g.tiles.key.pixelSizeX)), int64(math.Floor(minY / g.tiles.key.pixelSizeY))
+}
+
+// computeTileRow fills values (w*h) like computeValues at full resolution
+// from row startY to the end of the row of tiles it is in, computing the
+// tiles of that row that aren't cached yet, and returns the row after.
+// prepareTiles must have been called for the frame first. Samples sit on
+// the tile grid, so the frame can be up to a sample off the exact view bounds.
+func (g *Game) computeTileRow(values []float64, w, h, startY int) int {
+	key := g.tiles.key
+	originX, originY := g.tileOrigin()
+	ty := floorDiv(originY+int64(startY), tileSize)
+	endY := min(int((ty+1)*tileSize-originY), h)
+
+	var missing []tileCoord
+	for tx := floorDiv(originX, tileSize); tx <= floorDiv(originX+int64(w)-1, tileSize); tx++ {
+		if _, ok := g.tiles.tiles[tileCoord{tx, ty}]; !ok {
+			missing = append(missing, tileCoord{tx, ty})
+		}
+	}
+
+	sample := g.pointSampler(g.currentMaxIter())
+	rendered := make([][]float64, len(missing))
+	g.parallelRows(len(missing), func(start, end int) {
+		for i := start; i < end; i++ {
+			tile := make([]float64, tileSize*tileSize)
+			for y := 0; y < tileSize; y++ {
+				cy := float64(missing[i].y*tileSize+int64(y)) * key.pixelSizeY
+				for x := 0; x < tileSize; x++ {
+					cx := float64(missing[i].x*tileSize+int64(x)) * key.pixelSizeX
+					tile[y*tileSize+x] = sample(cx, cy)
+				}
+			}
+			rendered[i] = tile
+		}
+	})
+	for i, c := range missing {
+		g.tiles.tiles[c] = rendered[i]
+	}
+
+	for y := startY; y < endY; y++ {
+		row := int(originY+int64(y)-ty*tileSize) * tileSize
+		for x := 0; x < w; x++ {
+			gx := originX + int64(x)
+			tx := floorDiv(gx, tileSize)
+			values[y*w+x] = g.tiles.tiles[tileCoord{tx, ty}][row+int(gx-tx*tileSize)]
+		}
+	}
+	return endY
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,88 @@
+package main
+
+import "fmt"
+
+// tourStop is one of the famous places in the Mandelbrot set the guided tour visits
+type tourStop struct {
+	name             string
+	centerX, centerY float64
+	zoom             float64
+}
+
+var tourStops = []tourStop{
+	{"Seahorse Valley", -0.7453, 0.1127, 300},
+	{"Elephant Valley", 0.2925, 0.0164, 120},
+	{"Triple Spiral Valley", -0.0884, 0.6547, 350},
+	{"Period 3 Minibrot", -1.7549, 0, 90},
+}
+
+// the tour zooms out to the whole set between stops, and holds at each stop
+// for tourHold seconds
+const (
+	tourOverviewX, tourOverviewY = -0.75, 0.0
+	tourHold                     = 3.0
+)
+
+// phases of a guided tour
+const (
+	tourIn      = iota // diving into the stop
+	tourHolding        // resting at the stop
+	tourOut            // zooming back out to the whole set
+)
+
+// guidedTour loops through tourStops, diving in from the whole set to each
+// stop and back out again
+type guidedTour struct {
+	stop    int     // index into tourStops
+	phase   int     // tourIn, tourHolding or tourOut
+	hold    float64 // seconds left at the stop
+	seconds float64 // length of each dive in and out
+}
+
+// toggleTour starts the tour on the Mandelbrot set, or stops it where it is
+func (g *Game) toggleTour() {
+	if g.tour != nil {
+		g.tour = nil
+		g.dive = nil
+		return
+	}
+	g.switchFractal(FractalMandelbrot)
+	g.power = 2
+	// starting on the way out of the stop before the first one zooms out to
+	// the whole set, then dives into the first stop
+	g.tour = &guidedTour{stop: len(tourStops) - 1, phase: tourOut, seconds: g.tourSeconds}
+	g.diveTo(tourOverviewX, tourOverviewY, 1, g.tour.seconds)
+}
+
+// updateTour moves the tour to its next phase whenever the current dive ends
+func (g *Game) updateTour(elapsed float64) {
+	t := g.tour
+	if t == nil || g.dive != nil {
+		return
+	}
+	switch t.phase {
+	case tourIn:
+		t.phase, t.hold = tourHolding, tourHold
+	case tourHolding:
+		t.hold -= elapsed
+		if t.hold <= 0 {
+			t.phase = tourOut
+			g.diveTo(tourOverviewX, tourOverviewY, 1, t.seconds)
+		}
+	case tourOut:
+		t.stop = (t.stop + 1) % len(tourStops)
+		t.phase = tourIn
+		s := tourStops[t.stop]
+		g.diveTo(s.centerX, s.centerY, s.zoom, t.seconds)
+	}
+}
+
+// tourInfo names the stop the tour is at or heading to
+func (g *Game) tourInfo() string {
+	t := g.tour
+	i := t.stop
+	if t.phase == tourOut {
+		i = (i + 1) % len(tourStops)
+	}
+	return fmt.Sprintf("Tour: %s (%d/%d)", tourStops[i].name, i+1, len(tourStops))
+}
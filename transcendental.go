@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// functions of the transcendental julia sets, z -> c*f(z)
+const (
+	TransSin = iota
+	TransCos
+	TransExp
+
+	transFuncCount // number of transcendental functions, keep last
+)
+
+// these grow far too fast for the usual radius, and only a large one tells an
+// escaping orbit from one that swings out and back
+const transEscapeRadius = 50
+
+func transFuncName(fn int) string {
+	switch fn {
+	case TransSin:
+		return "c*sin(z)"
+	case TransCos:
+		return "c*cos(z)"
+	case TransExp:
+		return "c*exp(z)"
+	default:
+		return "Unknown"
+	}
+}
+
+// transSeed is a julia constant that shows the function's set off well
+func transSeed(fn int) (float64, float64) {
+	switch fn {
+	case TransSin:
+		return 1, 0.3
+	case TransCos:
+		return 0.9, 0.4
+	default:
+		return 0.38, 0
+	}
+}
+
+// overflowed reports whether z has blown up to an infinity or NaN. A NaN fails
+// every escape test, so without this its orbit would run on as a garbage
+// interior point.
+func overflowed(z complex128) bool {
+	return cmplx.IsInf(z) || cmplx.IsNaN(z)
+}
+
+// transcendental iterates z -> c*f(z) from z = x + y*i, coloured by the
+// iteration |z| leaves transEscapeRadius. An orbit that overflows has escaped
+// as surely.
+func transcendental(x, y, cx, cy float64, fn, maxIter int) float64 {
+	f := cmplx.Sin
+	switch fn {
+	case TransCos:
+		f = cmplx.Cos
+	case TransExp:
+		f = cmplx.Exp
+	}
+
+	z := complex(x, y)
+	c := complex(cx, cy)
+	for iteration := 0; iteration < maxIter; iteration++ {
+		if cmplx.Abs(z) > transEscapeRadius || overflowed(z) {
+			return float64(iteration) + 1
+		}
+		z = c * f(z)
+	}
+	return interiorValue(real(z), imag(z), maxIter)
+}
+
+// collatz iterates the smooth extension of the Collatz map to the complex
+// plane, z -> (2 + 7z - (2 + 5z)cos(pi*z))/4, which on the integers halves
+// even numbers and sends odd n to (3n + 1)/2
+func collatz(x, y float64, maxIter int) float64 {
+	z := complex(x, y)
+	for iteration := 0; iteration < maxIter; iteration++ {
+		if cmplx.Abs(z) > transEscapeRadius || overflowed(z) {
+			return float64(iteration) + 1
+		}
+		z = (2 + 7*z - (2+5*z)*cmplx.Cos(math.Pi*z)) / 4
+	}
+	return interiorValue(real(z), imag(z), maxIter)
+}
+
+// nextTransFunc switches to the next function, with a constant that suits it
+func (g *Game) nextTransFunc() {
+	g.transFunc = (g.transFunc + 1) % transFuncCount
+	g.juliaX, g.juliaY = transSeed(g.transFunc)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// workerCount is the size of the render worker pool, set by -workers
+var workerCount = runtime.NumCPU()
+
+// rowJob is one chunk of rows handed to the worker pool
+type rowJob struct {
+	fn           func(startY, endY int)
+	startY, endY int
+	wg           *sync.WaitGroup
+}
+
+// more chunks than workers evens out rows that cost more than others
+const chunksPerWorker = 4
+
+// workerPool is a set of render workers fed from one queue
+type workerPool struct {
+	jobs  chan rowJob
+	start sync.Once
+}
+
+// The window draws on renderPool. Exports and gif recordings run on
+// backgroundPool, so a frame never queues behind their chunks, and with half
+// the workers they leave CPUs free for the window.
+var renderPool, backgroundPool workerPool
+
+// queue starts the pool's workers on first use. They live for the whole run,
+// so frames don't pay for starting goroutines.
+func (p *workerPool) queue(workers int) chan<- rowJob {
+	p.start.Do(func() {
+		p.jobs = make(chan rowJob, workers*chunksPerWorker)
+		for i := 0; i < workers; i++ {
+			go func() {
+				for job := range p.jobs {
+					job.fn(job.startY, job.endY)
+					job.wg.Done()
+				}
+			}()
+		}
+	})
+	return p.jobs
+}
+
+// workerPool is the pool g renders on
+func (g *Game) workerPool() chan<- rowJob {
+	if g.background {
+		return backgroundPool.queue(max(workerCount/2, 1))
+	}
+	return renderPool.queue(workerCount)
+}